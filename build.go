@@ -0,0 +1,67 @@
+package microservicecore
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Build metadata, set at link time:
+//
+//	go build -ldflags "\
+//	    -X github.com/LUSHDigital/microservice-core-golang.GitCommit=$(git rev-parse HEAD) \
+//	    -X github.com/LUSHDigital/microservice-core-golang.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// When built with Go 1.18 or later, the commit falls back to the VCS
+// information the Go toolchain stamps into the binary.
+var (
+	GitCommit string // Commit the binary was built from.
+	BuildTime string // When the binary was built.
+)
+
+// startTime - When the process started.
+var startTime = time.Now()
+
+// Dependency - A module the binary was built with.
+type Dependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// BuildInfo - Represents build and runtime information about this microservice.
+type BuildInfo struct {
+	GitCommit    string       `json:"git_commit"`
+	BuildTime    string       `json:"build_time"`
+	CommitTime   string       `json:"commit_time,omitempty"`
+	GoVersion    string       `json:"go_version"`
+	Dependencies []Dependency `json:"dependencies"`
+	StartTime    time.Time    `json:"start_time"`
+	Uptime       string       `json:"uptime"`
+}
+
+var (
+	staticBuildInfo     BuildInfo
+	staticBuildInfoOnce sync.Once
+)
+
+// GetBuildInfo - Get the build and runtime information about this microservice.
+func GetBuildInfo() *BuildInfo {
+	staticBuildInfoOnce.Do(loadStaticBuildInfo)
+
+	info := staticBuildInfo
+	info.Uptime = time.Since(startTime).Round(time.Second).String()
+	return &info
+}
+
+// loadStaticBuildInfo - Collect the build information that cannot change
+// while the process is running.
+func loadStaticBuildInfo() {
+	staticBuildInfo = BuildInfo{
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		StartTime: startTime,
+	}
+
+	readBuildInfo(&staticBuildInfo)
+}
@@ -0,0 +1,34 @@
+//go:build go1.18
+// +build go1.18
+
+package microservicecore
+
+import "runtime/debug"
+
+// readBuildInfo - Add the dependencies and VCS information the Go toolchain
+// stamps into the binary.
+func readBuildInfo(info *BuildInfo) {
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, dep := range build.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		info.Dependencies = append(info.Dependencies, Dependency{
+			Path:    dep.Path,
+			Version: dep.Version,
+		})
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.GitCommit == "" {
+				info.GitCommit = setting.Value
+			}
+		case "vcs.time":
+			info.CommitTime = setting.Value
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !go1.18
+// +build !go1.18
+
+package microservicecore
+
+// readBuildInfo - Older toolchains do not stamp build information into the
+// binary, so only the link time metadata is reported.
+func readBuildInfo(info *BuildInfo) {}
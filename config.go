@@ -0,0 +1,30 @@
+package microservicecore
+
+import (
+	"net/http"
+
+	"github.com/LUSHDigital/microservice-core-golang/env"
+	"github.com/LUSHDigital/microservice-core-golang/response"
+)
+
+// ConfigPath - Path the config route is served on.
+const ConfigPath = "/admin/config"
+
+// ConfigRoute - Get the route listing every environment variable read through
+// the env package, with its source and the values of secrets masked. It is
+// meant for debugging and is not served unless added to the routes.
+func ConfigRoute() Route {
+	return Route{
+		Path:     ConfigPath,
+		Method:   http.MethodGet,
+		Summary:  "List the resolved configuration",
+		DataType: "config",
+		Response: []env.Variable{},
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			response.New(http.StatusOK, "", &response.Data{
+				Type:    "config",
+				Content: env.Variables(),
+			}).WriteTo(w)
+		},
+	}
+}
@@ -0,0 +1,52 @@
+package microservicecore
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/LUSHDigital/microservice-core-golang/env"
+	"github.com/LUSHDigital/microservice-core-golang/response"
+)
+
+func TestConfigRoute(t *testing.T) {
+	os.Setenv("TEST_CONFIG_DOMAIN", "example.com")
+	os.Setenv("TEST_CONFIG_PASSWORD", "s3cret")
+	defer os.Unsetenv("TEST_CONFIG_DOMAIN")
+	defer os.Unsetenv("TEST_CONFIG_PASSWORD")
+
+	env.Get("TEST_CONFIG_DOMAIN", "")
+	env.GetSecret("TEST_CONFIG_PASSWORD", "")
+
+	router, err := NewRouter(nil, []Route{ConfigRoute()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ConfigPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d got %d", http.StatusOK, rec.Code)
+	}
+
+	var resp response.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var variables []env.Variable
+	if err := resp.ExtractData("config", &variables); err != nil {
+		t.Fatal(err)
+	}
+
+	served := map[string]env.Variable{}
+	for _, v := range variables {
+		served[v.Name] = v
+	}
+	if v := served["TEST_CONFIG_DOMAIN"]; v.Value != "example.com" || v.Source != env.SourceEnvironment || v.Secret {
+		t.Errorf("unexpected domain variable %+v", v)
+	}
+	if v := served["TEST_CONFIG_PASSWORD"]; v.Value == "s3cret" || !v.Secret {
+		t.Errorf("expected the password to be masked got %+v", v)
+	}
+}
@@ -0,0 +1,233 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// HelpFlag - Command line flag printing the declared variables.
+	HelpFlag = "--help-env"
+
+	// CheckFlag - Command line flag listing the required variables that
+	// have not been set.
+	CheckFlag = "--check-env"
+)
+
+// Declaration - Documents a variable a service is configured with.
+type Declaration struct {
+	Name        string // Name of the environment variable.
+	Description string // What the variable configures.
+	Default     string // Value used when the variable is not set.
+	Required    bool   // Whether the service cannot start without it.
+	Secret      bool   // Whether the variable holds a secret.
+}
+
+var (
+	declarationsMu sync.RWMutex
+	declarations   = map[string]Declaration{}
+)
+
+// Declare - Register variables a service is configured with, so they are
+// documented and checked. Declaring a variable again replaces its
+// declaration, keeping the previous description when the new one is empty.
+func Declare(decls ...Declaration) {
+	declarationsMu.Lock()
+	defer declarationsMu.Unlock()
+	for _, decl := range decls {
+		if decl.Description == "" {
+			decl.Description = declarations[decl.Name].Description
+		}
+		if decl.Secret {
+			MarkSecret(decl.Name)
+		}
+		declarations[decl.Name] = decl
+	}
+}
+
+// DeclareStruct - Declare every variable of a struct tagged for Load, taking
+// descriptions from desc tags:
+//
+//	Timeout time.Duration `env:"CLIENT_TIMEOUT" default:"5s" desc:"Timeout of outgoing calls."`
+//
+// Load declares the variables of the structs it fills as well, so calling
+// DeclareStruct is only needed to document them before they are loaded.
+func DeclareStruct(cfg interface{}) error {
+	t := reflect.TypeOf(cfg)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot declare environment of %T: expected a struct", cfg)
+	}
+	declareStruct(t, "")
+	return nil
+}
+
+// Declarations - Get every declared variable, sorted by name.
+func Declarations() []Declaration {
+	declarationsMu.RLock()
+	defer declarationsMu.RUnlock()
+
+	list := make([]Declaration, 0, len(declarations))
+	for _, decl := range declarations {
+		list = append(list, decl)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Check - Report every required variable that has not been set, or cannot be
+// read, in a single *LoadError. Variables with a default are not reported
+// missing, as Load falls back to it, nor are variables whose value Load found
+// supplied from code. Returns nil when every one is available.
+func Check() error {
+	loadErr := &LoadError{}
+	for _, decl := range Declarations() {
+		if !decl.Required || supplied(decl.Name) {
+			continue
+		}
+		if _, _, ok, err := resolve(decl.Name); err != nil {
+			loadErr.Errors = append(loadErr.Errors, err)
+		} else if !ok && decl.Default == "" {
+			loadErr.Errors = append(loadErr.Errors, missingError(decl.Name))
+		}
+	}
+	if len(loadErr.Errors) > 0 {
+		return loadErr
+	}
+	return nil
+}
+
+// WriteMarkdown - Write the declared variables as a Markdown table.
+func WriteMarkdown(w io.Writer) error {
+	lines := []string{
+		"| Variable | Description | Default | Required |",
+		"| --- | --- | --- | --- |",
+	}
+	for _, decl := range Declarations() {
+		required := "no"
+		if decl.Required {
+			required = "yes"
+		}
+		def := ""
+		if decl.Default != "" {
+			def = "`" + displayDefault(decl) + "`"
+		}
+		lines = append(lines, fmt.Sprintf("| `%s` | %s | %s | %s |",
+			decl.Name, markdownCell(decl.Description), markdownCell(def), required))
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// WriteHelp - Write the declared variables in the style of command line help.
+func WriteHelp(w io.Writer) error {
+	lines := []string{"Environment variables:"}
+	for _, decl := range Declarations() {
+		var notes []string
+		if decl.Required {
+			notes = append(notes, "required")
+		}
+		if decl.Default != "" {
+			notes = append(notes, fmt.Sprintf("default %q", displayDefault(decl)))
+		}
+		if decl.Secret {
+			notes = append(notes, "secret, may be set as "+decl.Name+FileSuffix)
+		}
+
+		line := "  " + decl.Name
+		if len(notes) > 0 {
+			line += " (" + strings.Join(notes, ", ") + ")"
+		}
+		lines = append(lines, line)
+		if decl.Description != "" {
+			lines = append(lines, "    \t"+decl.Description)
+		}
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// HandleFlags - Handle HelpFlag and CheckFlag on a command line, writing the
+// declared variables or the outcome of Check to w. Reports whether either
+// flag was handled, in which case the service should exit rather than boot:
+//
+//	if handled, err := env.HandleFlags(os.Args[1:], os.Stdout); handled {
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    os.Exit(0)
+//	}
+func HandleFlags(args []string, w io.Writer) (bool, error) {
+	for _, arg := range args {
+		switch arg {
+		case HelpFlag:
+			return true, WriteHelp(w)
+		case CheckFlag:
+			if err := Check(); err != nil {
+				return true, err
+			}
+			_, err := io.WriteString(w, "every required environment variable is set\n")
+			return true, err
+		}
+	}
+	return false, nil
+}
+
+// declareStruct - Declare every tagged field of a struct type.
+func declareStruct(t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag, tagged := field.Tag.Lookup("env")
+		nestedPrefix, nested := field.Tag.Lookup("prefix")
+		if !tagged && (nested || field.Anonymous) {
+			nestedType := field.Type
+			if nestedType.Kind() == reflect.Ptr {
+				nestedType = nestedType.Elem()
+			}
+			if nestedType.Kind() == reflect.Struct {
+				declareStruct(nestedType, prefix+nestedPrefix)
+			}
+			continue
+		}
+		if !tagged {
+			continue
+		}
+
+		ft := parseFieldTag(tag)
+		if ft.name == "" {
+			continue
+		}
+		Declare(Declaration{
+			Name:        prefix + ft.name,
+			Description: field.Tag.Get("desc"),
+			Default:     field.Tag.Get("default"),
+			Required:    ft.required,
+			Secret:      ft.secret || field.Type == secretType,
+		})
+	}
+}
+
+// displayDefault - Get the default of a declaration as it may be displayed.
+func displayDefault(decl Declaration) string {
+	if decl.Secret {
+		return mask
+	}
+	return decl.Default
+}
+
+// markdownCell - Escape a value for a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.Replace(strings.Replace(s, "|", `\|`, -1), "\n", " ", -1)
+}
@@ -1,19 +1,21 @@
+// Package env retrieves and parses the environment variables a microservice
+// is configured with.
 package env
 
 import (
 	"log"
-	"os"
 )
 
-// MustGet - Return an environment variable by name.
-// If the requested variable does not exist, throw a fatal error.
-func MustGet(name string) string {
-	envVar, ok := os.LookupEnv(name)
-	if !ok {
-		log.Fatalf("environment variable (%s) has not been set", name)
+// MustGet - Return an environment variable by name, reading it from the file
+// named by NAME_FILE when NAME itself is not set.
+// If the requested variable does not exist or breaks a rule, throw a fatal error.
+func MustGet(name string, rules ...Rule) string {
+	envVar, ok, err := lookupValid(name, rules)
+	if err != nil {
+		log.Fatal(err)
 	}
-	if envVar == "" {
-		log.Fatalf("environment variable (%s) is empty", name)
+	if !ok {
+		log.Fatal(missingError(name))
 	}
 	return envVar
 }
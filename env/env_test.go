@@ -0,0 +1,1105 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetInt(t *testing.T) {
+	tt := []struct {
+		name        string
+		value       *string
+		expected    int
+		expectedErr bool
+	}{
+		{
+			name:     "not set",
+			expected: 10,
+		},
+		{
+			name:     "empty",
+			value:    strPtr(""),
+			expected: 10,
+		},
+		{
+			name:     "valid",
+			value:    strPtr(" 42 "),
+			expected: 42,
+		},
+		{
+			name:        "invalid",
+			value:       strPtr("forty-two"),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			defer setEnv("TEST_INT", tc.value)()
+
+			i, err := GetInt("TEST_INT", 10)
+			if tc.expectedErr {
+				parseErr, ok := err.(*ParseError)
+				if !ok {
+					t.Fatalf("expected *ParseError got (%T) %v", err, err)
+				}
+				if parseErr.Name != "TEST_INT" || parseErr.Value != *tc.value {
+					t.Errorf("expected the error to name TEST_INT=%q got %v", *tc.value, parseErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i != tc.expected {
+				t.Errorf("expected %d got %d", tc.expected, i)
+			}
+		})
+	}
+}
+
+func TestGetBool(t *testing.T) {
+	defer setEnv("TEST_BOOL", strPtr("true"))()
+	if b, err := GetBool("TEST_BOOL", false); err != nil || !b {
+		t.Errorf("expected true got %v (%v)", b, err)
+	}
+
+	defer setEnv("TEST_BOOL", strPtr("yes please"))()
+	if _, err := GetBool("TEST_BOOL", false); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestGetDuration(t *testing.T) {
+	defer setEnv("TEST_DURATION", strPtr("1m30s"))()
+	if d, err := GetDuration("TEST_DURATION", time.Second); err != nil || d != 90*time.Second {
+		t.Errorf("expected 1m30s got %v (%v)", d, err)
+	}
+
+	defer setEnv("TEST_DURATION", strPtr("90"))()
+	if _, err := GetDuration("TEST_DURATION", time.Second); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestGetURL(t *testing.T) {
+	def := &url.URL{Scheme: "https", Host: "default.example.com"}
+
+	tt := []struct {
+		name         string
+		value        *string
+		expectedHost string
+		expectedErr  bool
+	}{
+		{
+			name:         "not set",
+			expectedHost: "default.example.com",
+		},
+		{
+			name:         "absolute",
+			value:        strPtr("https://gateway.example.com/api"),
+			expectedHost: "gateway.example.com",
+		},
+		{
+			name:        "relative",
+			value:       strPtr("gateway.example.com"),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			defer setEnv("TEST_URL", tc.value)()
+
+			u, err := GetURL("TEST_URL", def)
+			if tc.expectedErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Host != tc.expectedHost {
+				t.Errorf("expected %s got %s", tc.expectedHost, u.Host)
+			}
+		})
+	}
+}
+
+func TestGetStringSlice(t *testing.T) {
+	defer setEnv("TEST_SLICE", strPtr("a, b,,c "))()
+	expected := []string{"a", "b", "c"}
+	if s := GetStringSlice("TEST_SLICE", nil); !reflect.DeepEqual(s, expected) {
+		t.Errorf("expected %v got %v", expected, s)
+	}
+
+	defer setEnv("TEST_SLICE", nil)()
+	if s := GetStringSlice("TEST_SLICE", []string{"x"}); !reflect.DeepEqual(s, []string{"x"}) {
+		t.Errorf("expected the default got %v", s)
+	}
+	if s, err := GetStrings("TEST_SLICE", []string{"x"}); err != nil || !reflect.DeepEqual(s, []string{"x"}) {
+		t.Errorf("expected the default got %v (%v)", s, err)
+	}
+}
+
+// setEnv - Set or unset an environment variable, returning a function
+// restoring its previous value:
+//
+//	defer setEnv("NAME", strPtr("value"))()
+func setEnv(name string, value *string) func() {
+	previous, ok := os.LookupEnv(name)
+	restore := func() {
+		if ok {
+			os.Setenv(name, previous)
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+
+	if value == nil {
+		os.Unsetenv(name)
+	} else {
+		os.Setenv(name, *value)
+	}
+	return restore
+}
+
+// strPtr - Get a pointer to a string.
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestLoad(t *testing.T) {
+	type database struct {
+		Host string `env:"HOST,required"`
+		Port int    `env:"PORT" default:"3306"`
+	}
+	type config struct {
+		Name     string        `env:"TEST_NAME,required"`
+		Timeout  time.Duration `env:"TEST_TIMEOUT" default:"5s"`
+		Gateway  *url.URL      `env:"TEST_GATEWAY_URL"`
+		Tags     []string      `env:"TEST_TAGS"`
+		Debug    bool          `env:"TEST_DEBUG"`
+		Database database      `prefix:"TEST_DB_"`
+		Ignored  string
+	}
+
+	defer setEnv("TEST_NAME", strPtr("example"))()
+	defer setEnv("TEST_TIMEOUT", nil)()
+	defer setEnv("TEST_GATEWAY_URL", strPtr("https://gateway.example.com"))()
+	defer setEnv("TEST_TAGS", strPtr("a,b"))()
+	defer setEnv("TEST_DEBUG", strPtr("true"))()
+	defer setEnv("TEST_DB_HOST", strPtr("db.example.com"))()
+	defer setEnv("TEST_DB_PORT", nil)()
+
+	var cfg config
+	if err := Load(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := config{
+		Name:     "example",
+		Timeout:  5 * time.Second,
+		Gateway:  &url.URL{Scheme: "https", Host: "gateway.example.com"},
+		Tags:     []string{"a", "b"},
+		Debug:    true,
+		Database: database{Host: "db.example.com", Port: 3306},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("expected %+v got %+v", expected, cfg)
+	}
+
+	// Values supplied from code are kept.
+	cfg = config{Name: "from-code"}
+	if err := Load(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "from-code" {
+		t.Errorf("expected from-code got %s", cfg.Name)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	type config struct {
+		Name    string        `env:"TEST_NAME,required"`
+		Scope   string        `env:"TEST_SCOPE,required"`
+		Port    int           `env:"TEST_PORT"`
+		Timeout time.Duration `env:"TEST_TIMEOUT"`
+	}
+
+	defer setEnv("TEST_NAME", nil)()
+	defer setEnv("TEST_SCOPE", strPtr(""))()
+	defer setEnv("TEST_PORT", strPtr("eighty"))()
+	defer setEnv("TEST_TIMEOUT", strPtr("soon"))()
+
+	var cfg config
+	err := Load(&cfg)
+	loadErr, ok := err.(*LoadError)
+	if !ok {
+		t.Fatalf("expected *LoadError got (%T) %v", err, err)
+	}
+	if len(loadErr.Errors) != 4 {
+		t.Fatalf("expected 4 errors got %d: %v", len(loadErr.Errors), loadErr)
+	}
+
+	expectedMissing := []*MissingError{
+		{Name: "TEST_NAME"},
+		{Name: "TEST_SCOPE", Empty: true},
+	}
+	for i, expected := range expectedMissing {
+		if !reflect.DeepEqual(loadErr.Errors[i], expected) {
+			t.Errorf("expected %v got %v", expected, loadErr.Errors[i])
+		}
+	}
+	for _, err := range loadErr.Errors[2:] {
+		if _, ok := err.(*ParseError); !ok {
+			t.Errorf("expected *ParseError got (%T) %v", err, err)
+		}
+	}
+
+	if err := Load(cfg); err == nil {
+		t.Error("expected an error when not passed a pointer")
+	}
+}
+
+func TestLookup_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile := func(name, content string, perm os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), perm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, perm); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tt := []struct {
+		name        string
+		value       *string
+		file        *string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "value from file",
+			file:     strPtr(writeFile("password", "s3cret\n", 0400)),
+			expected: "s3cret",
+		},
+		{
+			name:        "both forms set",
+			value:       strPtr("plain"),
+			file:        strPtr(writeFile("both", "s3cret", 0400)),
+			expectedErr: true,
+		},
+		{
+			name:        "world writable file",
+			file:        strPtr(writeFile("writable", "s3cret", 0666)),
+			expectedErr: true,
+		},
+		{
+			name:        "missing file",
+			file:        strPtr(filepath.Join(dir, "missing")),
+			expectedErr: true,
+		},
+		{
+			name:        "directory",
+			file:        strPtr(dir),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			defer setEnv("TEST_PASSWORD", tc.value)()
+			defer setEnv("TEST_PASSWORD"+FileSuffix, tc.file)()
+
+			secret, err := GetSecret("TEST_PASSWORD", "")
+			if tc.expectedErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(secret) != tc.expected {
+				t.Errorf("expected %q got %q", tc.expected, string(secret))
+			}
+		})
+	}
+}
+
+func TestSecret_Masked(t *testing.T) {
+	type config struct {
+		User     string `env:"TEST_USER"`
+		Password Secret `env:"TEST_PASSWORD"`
+		Token    string `env:"TEST_TOKEN,secret"`
+	}
+
+	defer setEnv("TEST_USER", strPtr("admin"))()
+	defer setEnv("TEST_PASSWORD", strPtr("s3cret"))()
+	defer setEnv("TEST_TOKEN", strPtr("t0ken"))()
+
+	var cfg config
+	if err := Load(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if string(cfg.Password) != "s3cret" {
+		t.Errorf("expected the secret to be loaded got %q", string(cfg.Password))
+	}
+
+	for _, formatted := range []string{
+		fmt.Sprintf("%v", cfg),
+		fmt.Sprintf("%+v", cfg),
+		fmt.Sprintf("%#v", cfg),
+	} {
+		if strings.Contains(formatted, "s3cret") {
+			t.Errorf("expected the secret to be masked got %s", formatted)
+		}
+	}
+	j, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(j), "s3cret") {
+		t.Errorf("expected the secret to be masked got %s", j)
+	}
+
+	if !IsSecret("TEST_PASSWORD") || !IsSecret("TEST_TOKEN") || IsSecret("TEST_USER") {
+		t.Error("expected only the password and token to be secrets")
+	}
+	if Mask("TEST_TOKEN", cfg.Token) == cfg.Token {
+		t.Error("expected the token to be masked")
+	}
+}
+
+func TestSecret_ParseErrorMasked(t *testing.T) {
+	type config struct {
+		DSN  *url.URL      `env:"TEST_SECRET_DSN,secret"`
+		Port int           `env:"TEST_SECRET_PORT,secret"`
+		TTL  time.Duration `env:"TEST_SECRET_TTL,secret"`
+	}
+
+	defer setEnv("TEST_SECRET_DSN", strPtr("postgres://user:s3cret@db host/"))()
+	defer setEnv("TEST_SECRET_PORT", strPtr("s3cret"))()
+	defer setEnv("TEST_SECRET_TTL", strPtr("s3cret"))()
+
+	err := Load(&config{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("expected the secret to be masked got %v", err)
+	}
+	for _, err := range err.(*LoadError).Errors {
+		if parseErr, ok := err.(*ParseError); !ok || parseErr.Value != mask {
+			t.Errorf("expected a masked *ParseError got (%T) %v", err, err)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	tt := []struct {
+		name        string
+		path        string
+		content     string
+		expected    map[string]string
+		expectedErr bool
+	}{
+		{
+			name: "dotenv",
+			path: ".env",
+			content: `# local settings
+SERVICE_NAME=products
+export SERVICE_TYPE = service # inline comment
+SOA_DOMAIN="local #1\tdomain"
+SOA_GATEWAY_URL='http://localhost:8080'
+EMPTY=
+`,
+			expected: map[string]string{
+				"SERVICE_NAME":    "products",
+				"SERVICE_TYPE":    "service",
+				"SOA_DOMAIN":      "local #1\tdomain",
+				"SOA_GATEWAY_URL": "http://localhost:8080",
+				"EMPTY":           "",
+			},
+		},
+		{
+			name:        "dotenv without value",
+			path:        ".env",
+			content:     "SERVICE_NAME\n",
+			expectedErr: true,
+		},
+		{
+			name:        "dotenv with invalid name",
+			path:        ".env.local",
+			content:     "SERVICE-NAME=products\n",
+			expectedErr: true,
+		},
+		{
+			name:    "json",
+			path:    "config.json",
+			content: `{"service": {"name": "products", "version": 2}, "soa-domain": "local", "debug": true, "hosts": ["a", "b"]}`,
+			expected: map[string]string{
+				"SERVICE_NAME":    "products",
+				"SERVICE_VERSION": "2",
+				"SOA_DOMAIN":      "local",
+				"DEBUG":           "true",
+				"HOSTS":           "a,b",
+			},
+		},
+		{
+			name:        "json with nested lists",
+			path:        "config.json",
+			content:     `{"hosts": [{"name": "a"}]}`,
+			expectedErr: true,
+		},
+		{
+			name: "yaml",
+			path: "config.yml",
+			content: `---
+service:
+  name: products # inline comment
+  version: "2"
+soa:
+  domain: 'local'
+hosts:
+  - a
+  - "b"
+scopes: [read, write]
+`,
+			expected: map[string]string{
+				"SERVICE_NAME":    "products",
+				"SERVICE_VERSION": "2",
+				"SOA_DOMAIN":      "local",
+				"HOSTS":           "a,b",
+				"SCOPES":          "read,write",
+			},
+		},
+		{
+			name:        "yaml with block scalar",
+			path:        "config.yaml",
+			content:     "description: |\n  products\n",
+			expectedErr: true,
+		},
+		{
+			name:        "yaml with a list of mappings",
+			path:        "config.yaml",
+			content:     "servers:\n  - name: a\n    port: 1\n  - name: b\n",
+			expectedErr: true,
+		},
+		{
+			name:        "yaml with a mapping under a list item",
+			path:        "config.yaml",
+			content:     "servers:\n  -\n    port: 1\n",
+			expectedErr: true,
+		},
+		{
+			name:        "yaml with a nested list",
+			path:        "config.yaml",
+			content:     "servers:\n  - - a\n",
+			expectedErr: true,
+		},
+		{
+			name:        "yaml with a flow mapping",
+			path:        "config.yaml",
+			content:     "service: {name: products}\n",
+			expectedErr: true,
+		},
+		{
+			name:        "yaml with a colon in a quoted key",
+			path:        "config.yaml",
+			content:     "\"service:name\": products\n",
+			expectedErr: true,
+		},
+		{
+			name:        "yaml with a null",
+			path:        "config.yaml",
+			content:     "service:\n  name: ~\n",
+			expectedErr: true,
+		},
+		{
+			name:        "yaml with a yes/no boolean",
+			path:        "config.yaml",
+			content:     "debug: yes\n",
+			expectedErr: true,
+		},
+		{
+			name:        "yaml with a yes/no boolean in a list",
+			path:        "config.yaml",
+			content:     "flags:\n  - on\n",
+			expectedErr: true,
+		},
+		{
+			name:    "yaml with quoted nulls and booleans",
+			path:    "config.yaml",
+			content: "name: \"~\"\ndebug: 'yes'\nflags: [\"no\", off2]\nurl: http://localhost:8080\n",
+			expected: map[string]string{
+				"NAME":  "~",
+				"DEBUG": "yes",
+				"FLAGS": "no,off2",
+				"URL":   "http://localhost:8080",
+			},
+		},
+		{
+			name: "toml",
+			path: "config.toml",
+			content: `debug = true
+
+[service]
+name = "products" # inline comment
+version = 2
+
+[soa]
+gateway.url = 'http://localhost:8080'
+hosts = ["a", "b"]
+`,
+			expected: map[string]string{
+				"DEBUG":           "true",
+				"SERVICE_NAME":    "products",
+				"SERVICE_VERSION": "2",
+				"SOA_GATEWAY_URL": "http://localhost:8080",
+				"SOA_HOSTS":       "a,b",
+			},
+		},
+		{
+			name:        "toml with array of tables",
+			path:        "config.toml",
+			content:     "[[hosts]]\nname = \"a\"\n",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := parseFile(tc.path, []byte(tc.content))
+			if tc.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error got %v", values)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(values, tc.expected) {
+				t.Errorf("expected %v got %v", tc.expected, values)
+			}
+		})
+	}
+}
+
+func TestLoadLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{
+		"config.yaml":      "service:\n  name: from-config\n  type: from-config\n  scope: from-config\n  version: from-config\n",
+		".env":             "SERVICE_TYPE=from-dotenv\nSERVICE_SCOPE=from-dotenv\nSERVICE_VERSION=from-dotenv\n",
+		"config.test.toml": "[service]\nscope = \"from-test-config\"\nversion = \"from-test-config\"\n",
+		".env.test":        "SERVICE_VERSION=from-test-dotenv\n",
+		"secret":           "s3cret\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defer Unload()
+	if err := LoadLocal(dir, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadFiles(filepath.Join(dir, "missing.json")); err != nil {
+		t.Errorf("expected missing files to be skipped got %v", err)
+	}
+	defer setEnv("SERVICE_NAME", nil)()
+	defer setEnv("SERVICE_NAME_FILE", nil)()
+	defer setEnv("SERVICE_TYPE", nil)()
+	defer setEnv("SERVICE_SCOPE", nil)()
+	defer setEnv("SERVICE_VERSION", strPtr("from-environment"))()
+	defer setEnv("TEST_TOKEN", nil)()
+	defer setEnv("TEST_TOKEN_FILE", strPtr(filepath.Join(dir, "secret")))()
+
+	tt := []struct {
+		name           string
+		expected       string
+		expectedSource string
+	}{
+		{"SERVICE_NAME", "from-config", filepath.Join(dir, "config.yaml")},
+		{"SERVICE_TYPE", "from-dotenv", filepath.Join(dir, ".env")},
+		{"SERVICE_SCOPE", "from-test-config", filepath.Join(dir, "config.test.toml")},
+		{"SERVICE_VERSION", "from-environment", SourceEnvironment},
+		{"TEST_TOKEN", "s3cret", filepath.Join(dir, "secret")},
+		{"TEST_UNSET", "", ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if value := Get(tc.name, ""); value != tc.expected {
+				t.Errorf("expected %q got %q", tc.expected, value)
+			}
+			if source := Source(tc.name); source != tc.expectedSource {
+				t.Errorf("expected source %q got %q", tc.expectedSource, source)
+			}
+		})
+	}
+}
+
+// storeConfig - Config refusing timeouts of a minute or more.
+type storeConfig struct {
+	Name     string        `env:"TEST_STORE_NAME,required"`
+	Timeout  time.Duration `env:"TEST_STORE_TIMEOUT" default:"5s"`
+	Password Secret        `env:"TEST_STORE_PASSWORD"`
+}
+
+// Validate - Refuse timeouts of a minute or more.
+func (c *storeConfig) Validate() error {
+	if c.Timeout >= time.Minute {
+		return fmt.Errorf("timeout %s is too long", c.Timeout)
+	}
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	passwordPath := filepath.Join(dir, "password")
+	write := func(path, content string) {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(path, "TEST_STORE_TIMEOUT=1s\n")
+	write(passwordPath, "s3cret\n")
+
+	defer Unload()
+	if err := LoadFiles(path); err != nil {
+		t.Fatal(err)
+	}
+	defer setEnv("TEST_STORE_NAME", strPtr("products"))()
+	defer setEnv("TEST_STORE_TIMEOUT", nil)()
+	defer setEnv("TEST_STORE_PASSWORD_FILE", strPtr(passwordPath))()
+
+	store, err := NewStore(&storeConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg := store.Get().(*storeConfig); cfg.Timeout != time.Second || cfg.Name != "products" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	if err := store.Subscribe(func(cfg storeConfig) {}); err == nil {
+		t.Error("expected an error subscribing a function of the wrong type")
+	}
+	var notified []string
+	if err := store.Subscribe(func(previous, cfg *storeConfig) {
+		notified = append(notified, fmt.Sprintf("%s->%s", previous.Timeout, cfg.Timeout))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("changed", func(t *testing.T) {
+		write(path, "TEST_STORE_TIMEOUT=2s\n")
+		if err := store.Reload(); err != nil {
+			t.Fatal(err)
+		}
+		if timeout := store.Get().(*storeConfig).Timeout; timeout != 2*time.Second {
+			t.Errorf("expected 2s got %s", timeout)
+		}
+		if !reflect.DeepEqual(notified, []string{"1s->2s"}) {
+			t.Errorf("expected one notification got %v", notified)
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		write(path, "# unchanged\nTEST_STORE_TIMEOUT=2s\n")
+		if err := store.Reload(); err != nil {
+			t.Fatal(err)
+		}
+		if len(notified) != 1 {
+			t.Errorf("expected no further notifications got %v", notified)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		write(path, "TEST_STORE_TIMEOUT=5m\n")
+		if err := store.Reload(); err == nil {
+			t.Fatal("expected an error")
+		}
+		if timeout := store.Get().(*storeConfig).Timeout; timeout != 2*time.Second {
+			t.Errorf("expected the previous config to be kept got %s", timeout)
+		}
+		if value := Get("TEST_STORE_TIMEOUT", ""); value != "2s" {
+			t.Errorf("expected the previous file values to be kept got %q", value)
+		}
+	})
+
+	t.Run("files watched", func(t *testing.T) {
+		changed := make(chan *storeConfig, 10)
+		if err := store.Subscribe(func(cfg *storeConfig) { changed <- cfg }); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go store.Watch(ctx, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+
+		// Written in place, so the watcher may see the file truncated.
+		write(path, "TEST_STORE_TIMEOUT=30s\n")
+		select {
+		case cfg := <-changed:
+			if cfg.Timeout != 30*time.Second {
+				t.Errorf("expected 30s got %s", cfg.Timeout)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the config file change to be picked up")
+		}
+
+		write(passwordPath, "r0tated\n")
+		select {
+		case cfg := <-changed:
+			if cfg.Password != "r0tated" {
+				t.Errorf("expected the rotated password got %q", string(cfg.Password))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the secret file change to be picked up")
+		}
+	})
+}
+
+func TestVariables(t *testing.T) {
+	type config struct {
+		Region string `env:"TEST_VARIABLES_REGION" default:"eu-west-1"`
+	}
+	defer setEnv("TEST_VARIABLES_DOMAIN", strPtr("example.com"))()
+	defer setEnv("TEST_VARIABLES_TOKEN", strPtr("s3cret"))()
+	defer setEnv("TEST_VARIABLES_REGION", nil)()
+	defer setEnv("TEST_VARIABLES_UNSET", nil)()
+
+	Get("TEST_VARIABLES_DOMAIN", "")
+	GetSecret("TEST_VARIABLES_TOKEN", "")
+	GetInt("TEST_VARIABLES_UNSET", 1)
+	if err := Load(&config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]Variable{
+		"TEST_VARIABLES_DOMAIN": {Name: "TEST_VARIABLES_DOMAIN", Value: "example.com", Source: SourceEnvironment, Set: true},
+		"TEST_VARIABLES_TOKEN":  {Name: "TEST_VARIABLES_TOKEN", Value: mask, Source: SourceEnvironment, Set: true, Secret: true},
+		"TEST_VARIABLES_REGION": {Name: "TEST_VARIABLES_REGION", Value: "eu-west-1", Source: SourceDefault, Set: true},
+		"TEST_VARIABLES_UNSET":  {Name: "TEST_VARIABLES_UNSET"},
+	}
+	for _, v := range Variables() {
+		if want, ok := expected[v.Name]; ok {
+			if v != want {
+				t.Errorf("expected %+v got %+v", want, v)
+			}
+			delete(expected, v.Name)
+		}
+	}
+	if len(expected) > 0 {
+		t.Errorf("expected variables missing: %v", expected)
+	}
+}
+
+func TestRules(t *testing.T) {
+	tt := []struct {
+		name    string
+		rule    Rule
+		valid   []string
+		invalid []string
+	}{
+		{
+			name:    "one of",
+			rule:    OneOf("public", "internal"),
+			valid:   []string{"public", "internal"},
+			invalid: []string{"private", "Public", ""},
+		},
+		{
+			name:    "range",
+			rule:    Range(1, 65535),
+			valid:   []string{"1", "8080", "65535", " 80 "},
+			invalid: []string{"0", "65536", "http"},
+		},
+		{
+			name:    "duration range",
+			rule:    DurationRange(time.Second, time.Minute),
+			valid:   []string{"1s", "30s", "1m"},
+			invalid: []string{"500ms", "2m", "30"},
+		},
+		{
+			name:    "match",
+			rule:    Match(`[a-z]+(-[a-z]+)*`),
+			valid:   []string{"products", "product-reviews"},
+			invalid: []string{"Products", "products-", "products service"},
+		},
+		{
+			name:    "url",
+			rule:    URL("https"),
+			valid:   []string{"https://gateway.example.com", "HTTPS://gateway.example.com/v1"},
+			invalid: []string{"http://gateway.example.com", "gateway.example.com", "https://"},
+		},
+		{
+			name:    "semver",
+			rule:    SemVer(),
+			valid:   []string{"0.0.1", "1.4.0-rc.1", "2.0.0+build.5"},
+			invalid: []string{"1", "1.4", "v1.4.0", "01.4.0"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, value := range tc.valid {
+				if err := tc.rule(value); err != nil {
+					t.Errorf("expected %q to be valid got %v", value, err)
+				}
+			}
+			for _, value := range tc.invalid {
+				if err := tc.rule(value); err == nil {
+					t.Errorf("expected %q to be invalid", value)
+				}
+			}
+		})
+	}
+}
+
+func TestGetters_Rules(t *testing.T) {
+	defer setEnv("TEST_RULES_SCOPE", strPtr("private"))()
+	defer setEnv("TEST_RULES_PORT", strPtr("70000"))()
+	defer setEnv("TEST_RULES_HOSTS", strPtr("a.example.com, b"))()
+	defer setEnv("TEST_RULES_UNSET", nil)()
+
+	if scope := Get("TEST_RULES_SCOPE", "internal", OneOf("public", "internal")); scope != "internal" {
+		t.Errorf("expected the default for an invalid value got %q", scope)
+	}
+	if hosts := GetStringSlice("TEST_RULES_HOSTS", nil, Match(`[a-z]+\.example\.com`)); hosts != nil {
+		t.Errorf("expected the default for an invalid item got %v", hosts)
+	}
+	scope, scopeErr := GetString("TEST_RULES_SCOPE", "internal", OneOf("public", "internal"))
+	if _, ok := scopeErr.(*ValidationError); !ok || scope != "internal" {
+		t.Errorf("expected the default and a *ValidationError got %q (%T) %v", scope, scopeErr, scopeErr)
+	}
+	hosts, hostsErr := GetStrings("TEST_RULES_HOSTS", nil, Match(`[a-z]+\.example\.com`))
+	if _, ok := hostsErr.(*ValidationError); !ok || hosts != nil {
+		t.Errorf("expected the default and a *ValidationError got %v (%T) %v", hosts, hostsErr, hostsErr)
+	}
+	_, portErr := GetInt("TEST_RULES_PORT", 8080, Range(1, 65535))
+	if _, ok := portErr.(*ValidationError); !ok {
+		t.Errorf("expected *ValidationError got (%T) %v", portErr, portErr)
+	}
+	version, versionErr := GetSecret("TEST_RULES_UNSET", "1.0.0", SemVer())
+	if versionErr != nil || version != "1.0.0" {
+		t.Errorf("expected the default to be returned unchecked got %q (%v)", string(version), versionErr)
+	}
+
+	err := Collect(scopeErr, hostsErr, portErr, versionErr)
+	loadErr, ok := err.(*LoadError)
+	if !ok || len(loadErr.Errors) != 3 {
+		t.Fatalf("expected a *LoadError with three errors got (%T) %v", err, err)
+	}
+	if Collect(nil, nil) != nil {
+		t.Error("expected no error when collecting nil errors")
+	}
+}
+
+func TestLoad_Validate(t *testing.T) {
+	type config struct {
+		Scope    string        `env:"TEST_VALIDATE_SCOPE" validate:"oneof=public|internal"`
+		Version  string        `env:"TEST_VALIDATE_VERSION" default:"1" validate:"semver"`
+		Gateway  *url.URL      `env:"TEST_VALIDATE_GATEWAY" validate:"url=https"`
+		Port     int           `env:"TEST_VALIDATE_PORT" validate:"range=1:"`
+		Timeout  time.Duration `env:"TEST_VALIDATE_TIMEOUT" validate:"range=1s:1m"`
+		Name     string        `env:"TEST_VALIDATE_NAME" validate:"match=[a-z]{1,3}(,[a-z]+)*"`
+		Hosts    []string      `env:"TEST_VALIDATE_HOSTS" validate:"oneof=a|b"`
+		Password string        `env:"TEST_VALIDATE_PASSWORD,secret" validate:"match=[0-9]+"`
+	}
+	defer setEnv("TEST_VALIDATE_SCOPE", strPtr("private"))()
+	defer setEnv("TEST_VALIDATE_VERSION", nil)()
+	defer setEnv("TEST_VALIDATE_GATEWAY", strPtr("http://gateway.example.com"))()
+	defer setEnv("TEST_VALIDATE_PORT", strPtr("8080"))()
+	defer setEnv("TEST_VALIDATE_TIMEOUT", strPtr("2m"))()
+	defer setEnv("TEST_VALIDATE_NAME", strPtr("abc,def"))()
+	defer setEnv("TEST_VALIDATE_HOSTS", strPtr("a,c"))()
+	defer setEnv("TEST_VALIDATE_PASSWORD", strPtr("s3cret"))()
+
+	cfg := &config{}
+	err := Load(cfg)
+	loadErr, ok := err.(*LoadError)
+	if !ok {
+		t.Fatalf("expected *LoadError got (%T) %v", err, err)
+	}
+
+	var invalid []string
+	for _, err := range loadErr.Errors {
+		validationErr, ok := err.(*ValidationError)
+		if !ok {
+			t.Fatalf("expected *ValidationError got (%T) %v", err, err)
+		}
+		invalid = append(invalid, validationErr.Name)
+		if validationErr.Value == "s3cret" {
+			t.Error("expected the secret value to be masked")
+		}
+	}
+	expected := []string{
+		"TEST_VALIDATE_SCOPE",
+		"TEST_VALIDATE_VERSION",
+		"TEST_VALIDATE_GATEWAY",
+		"TEST_VALIDATE_TIMEOUT",
+		"TEST_VALIDATE_HOSTS",
+		"TEST_VALIDATE_PASSWORD",
+	}
+	if !reflect.DeepEqual(invalid, expected) {
+		t.Errorf("expected %v to be invalid got %v", expected, invalid)
+	}
+	if cfg.Port != 8080 || cfg.Name != "abc,def" {
+		t.Errorf("expected the valid fields to be loaded got %+v", cfg)
+	}
+
+	type badTag struct {
+		Scope string `env:"TEST_VALIDATE_SCOPE" validate:"oneof"`
+	}
+	if err := Load(&badTag{}); err == nil {
+		t.Error("expected an error for an invalid validate tag")
+	}
+}
+
+func TestDeclare(t *testing.T) {
+	type config struct {
+		Domain   string        `env:"DOMAIN,required" desc:"Top level | domain."`
+		Timeout  time.Duration `env:"TIMEOUT" default:"5s" desc:"Timeout of calls."`
+		Password Secret        `env:"PASSWORD" default:"changeme"`
+	}
+	type parent struct {
+		Config config `prefix:"TEST_DECLARE_"`
+	}
+	if err := DeclareStruct(parent{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeclareStruct("config"); err == nil {
+		t.Error("expected an error declaring a string")
+	}
+	Declare(Declaration{Name: "TEST_DECLARE_TIMEOUT", Default: "10s"})
+
+	declared := map[string]Declaration{}
+	for _, decl := range Declarations() {
+		declared[decl.Name] = decl
+	}
+	expected := []Declaration{
+		{Name: "TEST_DECLARE_DOMAIN", Description: "Top level | domain.", Required: true},
+		{Name: "TEST_DECLARE_PASSWORD", Default: "changeme", Secret: true},
+		{Name: "TEST_DECLARE_TIMEOUT", Description: "Timeout of calls.", Default: "10s"},
+	}
+	for _, want := range expected {
+		if decl := declared[want.Name]; decl != want {
+			t.Errorf("expected %+v got %+v", want, decl)
+		}
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := WriteMarkdown(&b); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range []string{
+			"| Variable | Description | Default | Required |",
+			"| `TEST_DECLARE_DOMAIN` | Top level \\| domain. |  | yes |",
+			"| `TEST_DECLARE_PASSWORD` |  | `" + mask + "` | no |",
+			"| `TEST_DECLARE_TIMEOUT` | Timeout of calls. | `10s` | no |",
+		} {
+			if !strings.Contains(b.String(), line+"\n") {
+				t.Errorf("expected the line %q in\n%s", line, b.String())
+			}
+		}
+	})
+
+	t.Run("help", func(t *testing.T) {
+		var b bytes.Buffer
+		if handled, err := HandleFlags([]string{"-v", HelpFlag}, &b); !handled || err != nil {
+			t.Fatalf("expected the flag to be handled got %v (%v)", handled, err)
+		}
+		for _, line := range []string{
+			"  TEST_DECLARE_DOMAIN (required)\n    \tTop level | domain.",
+			"  TEST_DECLARE_PASSWORD (default \"" + mask + "\", secret, may be set as TEST_DECLARE_PASSWORD_FILE)",
+			"  TEST_DECLARE_TIMEOUT (default \"10s\")\n    \tTimeout of calls.",
+		} {
+			if !strings.Contains(b.String(), line+"\n") {
+				t.Errorf("expected %q in\n%s", line, b.String())
+			}
+		}
+	})
+
+	t.Run("check", func(t *testing.T) {
+		defer setEnv("TEST_DECLARE_DOMAIN", nil)()
+		defer setEnv("TEST_DECLARE_DOMAIN_FILE", nil)()
+		defer setEnv("TEST_DECLARE_REGION", nil)()
+		Declare(Declaration{Name: "TEST_DECLARE_REGION", Default: "eu-west-1", Required: true})
+		handled, err := HandleFlags([]string{CheckFlag}, ioutil.Discard)
+		if !handled {
+			t.Fatal("expected the flag to be handled")
+		}
+		loadErr, ok := err.(*LoadError)
+		if !ok {
+			t.Fatalf("expected *LoadError got (%T) %v", err, err)
+		}
+		var missing bool
+		for _, err := range loadErr.Errors {
+			if missingErr, ok := err.(*MissingError); ok && missingErr.Name == "TEST_DECLARE_DOMAIN" {
+				missing = true
+			}
+		}
+		if !missing {
+			t.Errorf("expected TEST_DECLARE_DOMAIN to be reported missing got %v", err)
+		}
+		if strings.Contains(err.Error(), "TEST_DECLARE_REGION") {
+			t.Errorf("expected TEST_DECLARE_REGION to fall back to its default got %v", err)
+		}
+
+		defer setEnv("TEST_DECLARE_DOMAIN", strPtr("example.com"))()
+		if err := Check(); err != nil && strings.Contains(err.Error(), "TEST_DECLARE_DOMAIN") {
+			t.Errorf("expected TEST_DECLARE_DOMAIN to be found got %v", err)
+		}
+	})
+
+	t.Run("check supplied from code", func(t *testing.T) {
+		type supplied struct {
+			Name string `env:"TEST_DECLARE_SUPPLIED,required"`
+		}
+		defer setEnv("TEST_DECLARE_SUPPLIED", nil)()
+		defer setEnv("TEST_DECLARE_SUPPLIED_FILE", nil)()
+		if err := Load(&supplied{Name: "from-code"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := Check(); err != nil && strings.Contains(err.Error(), "TEST_DECLARE_SUPPLIED") {
+			t.Errorf("expected TEST_DECLARE_SUPPLIED to be supplied from code got %v", err)
+		}
+	})
+
+	if handled, _ := HandleFlags([]string{"-v"}, ioutil.Discard); handled {
+		t.Error("expected other flags to be ignored")
+	}
+}
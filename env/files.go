@@ -0,0 +1,699 @@
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SourceEnvironment - Source of values set in the process environment.
+const SourceEnvironment = "environment"
+
+// layer - Variables read from a config file.
+type layer struct {
+	path   string
+	values map[string]string
+}
+
+var (
+	layersMu sync.RWMutex
+	layers   []layer  // Lowest precedence first.
+	files    []string // Every config file requested, including missing ones.
+)
+
+// LoadFiles - Layer config files beneath the process environment, so their
+// values are used for variables the process environment does not set. Later
+// files take precedence over earlier ones. Files ending in .json, .yaml, .yml
+// or .toml are parsed as such and every other file as a .env file. Nested keys
+// are joined with underscores and upper cased, so {"soa": {"domain": ""}}
+// provides SOA_DOMAIN. Files that do not exist are skipped.
+func LoadFiles(paths ...string) error {
+	loaded, err := readFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	layersMu.Lock()
+	defer layersMu.Unlock()
+	layers = append(layers, loaded...)
+	files = append(files, paths...)
+	return nil
+}
+
+// LoadLocal - Load the config files for local development from a directory,
+// in increasing order of precedence:
+//
+//	config.json, config.yaml, config.yml, config.toml
+//	.env
+//	config.<environment>.json, config.<environment>.yaml, ...
+//	.env.<environment>
+//
+// The environment specific files are skipped when environment is empty.
+func LoadLocal(dir, environment string) error {
+	paths := configPaths(dir, "config")
+	paths = append(paths, filepath.Join(dir, ".env"))
+	if environment != "" {
+		paths = append(paths, configPaths(dir, "config."+environment)...)
+		paths = append(paths, filepath.Join(dir, ".env."+environment))
+	}
+	return LoadFiles(paths...)
+}
+
+// Unload - Forget every config file loaded so far.
+func Unload() {
+	layersMu.Lock()
+	defer layersMu.Unlock()
+	layers = nil
+	files = nil
+}
+
+// Source - Report where the value of a variable comes from: SourceEnvironment,
+// or the path of the config file or secret file it is read from. The source is
+// empty when the variable is not set.
+func Source(name string) string {
+	_, source, ok, _ := resolve(name)
+	if !ok {
+		return ""
+	}
+	return source
+}
+
+// readFiles - Read and parse the config files that exist.
+func readFiles(paths []string) ([]layer, error) {
+	var loaded []layer
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("config file (%s) is unreadable: %v", path, err)
+		}
+
+		values, err := parseFile(path, content)
+		if err != nil {
+			return nil, fmt.Errorf("config file (%s) is invalid: %v", path, err)
+		}
+		loaded = append(loaded, layer{path: path, values: values})
+	}
+	return loaded, nil
+}
+
+// reloadFiles - Read every config file requested so far again, returning a
+// function restoring the values read before.
+func reloadFiles() (func(), error) {
+	layersMu.RLock()
+	paths := files
+	layersMu.RUnlock()
+
+	loaded, err := readFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	layersMu.Lock()
+	defer layersMu.Unlock()
+	previous := layers
+	layers = loaded
+	return func() {
+		layersMu.Lock()
+		defer layersMu.Unlock()
+		layers = previous
+	}, nil
+}
+
+// fileStamp - Modification time and size of a config file, which are zero
+// when the file does not exist.
+type fileStamp struct {
+	modTime int64
+	size    int64
+}
+
+// fileStamps - Get the stamp of every config file requested so far, and of
+// every file a variable was read from.
+func fileStamps() map[string]fileStamp {
+	layersMu.RLock()
+	paths := append([]string(nil), files...)
+	layersMu.RUnlock()
+
+	secretFilesMu.Lock()
+	for _, path := range secretFiles {
+		paths = append(paths, path)
+	}
+	secretFilesMu.Unlock()
+
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = fileStamp{modTime: info.ModTime().UnixNano(), size: info.Size()}
+		} else {
+			stamps[path] = fileStamp{}
+		}
+	}
+	return stamps
+}
+
+// configPaths - Get the paths of the config files with the provided base name
+// in every supported format.
+func configPaths(dir, base string) []string {
+	var paths []string
+	for _, ext := range []string{".json", ".yaml", ".yml", ".toml"} {
+		paths = append(paths, filepath.Join(dir, base+ext))
+	}
+	return paths
+}
+
+// find - Return the value of a variable from the source with the highest
+// precedence setting it, treating empty values as unset. The rank orders
+// sources by precedence.
+func find(name string) (value, source string, rank int, ok bool) {
+	layersMu.RLock()
+	defer layersMu.RUnlock()
+
+	if value, ok := os.LookupEnv(name); ok && value != "" {
+		return value, SourceEnvironment, len(layers) + 1, true
+	}
+	for i := len(layers) - 1; i >= 0; i-- {
+		if value, ok := layers[i].values[name]; ok && value != "" {
+			return value, layers[i].path, i + 1, true
+		}
+	}
+	return "", "", 0, false
+}
+
+// parseFile - Parse the content of a config file according to its extension.
+func parseFile(path string, content []byte) (map[string]string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return parseJSON(content)
+	case ".yaml", ".yml":
+		return parseYAML(content)
+	case ".toml":
+		return parseTOML(content)
+	}
+	return parseDotEnv(content)
+}
+
+// parseDotEnv - Parse NAME=value lines, optionally prefixed with export.
+// Values may be quoted, and unquoted values end at a # comment.
+func parseDotEnv(content []byte) (map[string]string, error) {
+	values := map[string]string{}
+	err := eachLine(content, func(line string) error {
+		line = strings.TrimPrefix(line, "export ")
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return fmt.Errorf("expected NAME=value")
+		}
+		name := strings.TrimSpace(line[:eq])
+		if !validName(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+
+		value, err := parseScalar(line[eq+1:])
+		if err != nil {
+			return err
+		}
+		values[name] = value
+		return nil
+	})
+	return values, err
+}
+
+// parseJSON - Parse a JSON object, flattening nested objects.
+func parseJSON(content []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	if err := flattenJSON("", document, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flattenJSON - Add the values of a decoded JSON object to values, naming
+// nested values after their path.
+func flattenJSON(prefix string, document map[string]interface{}, values map[string]string) error {
+	for key, value := range document {
+		name := joinName(prefix, key)
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if err := flattenJSON(name, value, values); err != nil {
+				return err
+			}
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				s, ok := jsonScalar(item)
+				if !ok {
+					return fmt.Errorf("key (%s) holds a list of non scalar values", name)
+				}
+				items[i] = s
+			}
+			values[name] = strings.Join(items, ",")
+		default:
+			values[name], _ = jsonScalar(value)
+		}
+	}
+	return nil
+}
+
+// jsonScalar - Format a decoded JSON scalar as a string.
+func jsonScalar(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return value, true
+	case json.Number:
+		return value.String(), true
+	case bool:
+		return strconv.FormatBool(value), true
+	}
+	return "", false
+}
+
+// parseYAML - Parse the subset of YAML config files are written in: nested
+// mappings, scalars, and block or flow sequences of scalars. Anything else,
+// such as sequences of mappings, flow mappings or keys holding colons, is
+// rejected, as are unquoted nulls and yes/no booleans, which YAML parsers
+// disagree on.
+func parseYAML(content []byte) (map[string]string, error) {
+	type parent struct {
+		indent int
+		name   string
+	}
+	var (
+		values     = map[string]string{}
+		parents    []parent
+		lists      = map[string][]string{}
+		itemIndent = -1 // Indentation of the last list item, -1 outside lists.
+	)
+
+	err := eachRawLine(content, func(raw string) error {
+		trimmed := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return fmt.Errorf("tabs are not allowed in indentation")
+		}
+		indent := len(raw) - len(trimmed)
+		line := strings.TrimSpace(stripComment(trimmed))
+		if line == "" || line == "---" {
+			return nil
+		}
+
+		if line == "-" || strings.HasPrefix(line, "- ") {
+			for len(parents) > 0 && parents[len(parents)-1].indent > indent {
+				parents = parents[:len(parents)-1]
+			}
+			if len(parents) == 0 {
+				return fmt.Errorf("list item outside of a key")
+			}
+			name := parents[len(parents)-1].name
+			item, err := parseListItem(name, strings.TrimPrefix(line, "-"))
+			if err != nil {
+				return err
+			}
+			lists[name] = append(lists[name], item)
+			itemIndent = indent
+			return nil
+		}
+
+		if itemIndent >= 0 && indent > itemIndent {
+			return fmt.Errorf("list items holding mappings are not supported")
+		}
+		itemIndent = -1
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+		colon := yamlColon(line)
+		if colon < 0 {
+			return fmt.Errorf("expected key: value")
+		}
+		key, err := unquote(strings.TrimSpace(line[:colon]))
+		if err != nil {
+			return err
+		}
+		if strings.Contains(key, ":") {
+			return fmt.Errorf("key %q holds a colon, which is not supported", key)
+		}
+		prefix := ""
+		if len(parents) > 0 {
+			prefix = parents[len(parents)-1].name
+		}
+		name := joinName(prefix, key)
+
+		rest := strings.TrimSpace(line[colon+1:])
+		switch {
+		case rest == "":
+			parents = append(parents, parent{indent: indent, name: name})
+		case rest == "|" || rest == ">" || strings.HasPrefix(rest, "&") || strings.HasPrefix(rest, "*"):
+			return fmt.Errorf("key (%s) uses unsupported YAML syntax %q", name, rest)
+		case strings.HasPrefix(rest, "{"):
+			return fmt.Errorf("key (%s) uses unsupported flow mappings", name)
+		case strings.HasPrefix(rest, "["):
+			items, err := parseFlowList(rest)
+			if err != nil {
+				return err
+			}
+			for _, item := range splitOutsideQuotes(rest[1:len(rest)-1], ',') {
+				if err := checkYAMLScalar(name, strings.TrimSpace(item)); err != nil {
+					return err
+				}
+			}
+			values[name] = strings.Join(items, ",")
+		default:
+			if err := checkYAMLScalar(name, strings.TrimSpace(stripComment(rest))); err != nil {
+				return err
+			}
+			value, err := parseScalar(rest)
+			if err != nil {
+				return err
+			}
+			values[name] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name, items := range lists {
+		values[name] = strings.Join(items, ",")
+	}
+	return values, nil
+}
+
+// parseListItem - Parse a YAML list item, which must be a scalar.
+func parseListItem(name, item string) (string, error) {
+	item = strings.TrimSpace(item)
+	if item == "" || item[0] == '"' || item[0] == '\'' {
+		return parseScalar(item)
+	}
+	if strings.ContainsAny(item[:1], "-[{|>&*") {
+		return "", fmt.Errorf("list (%s) uses unsupported YAML syntax %q", name, item)
+	}
+	value := strings.TrimSpace(stripComment(item))
+	if strings.HasSuffix(value, ":") || strings.Contains(value, ": ") {
+		return "", fmt.Errorf("list (%s) holds mappings, which are not supported", name)
+	}
+	if err := checkYAMLScalar(name, value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// yamlColon - Get the index of the colon ending the key of a YAML mapping
+// line, which is followed by a space or ends the line, or -1 when there is
+// none. Colons within a quoted key are skipped.
+func yamlColon(line string) int {
+	start := 0
+	if line[0] == '"' || line[0] == '\'' {
+		end := closingQuote(line)
+		if end < 0 {
+			return -1
+		}
+		start = end + 1
+	}
+	for i := start; i < len(line); i++ {
+		if line[i] == ':' && (i+1 == len(line) || line[i+1] == ' ') {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkYAMLScalar - Reject unquoted YAML scalars that do not read as the
+// string they are written as: nulls, and the yes/no booleans of YAML 1.1.
+func checkYAMLScalar(name, value string) error {
+	switch value {
+	case "~", "null", "Null", "NULL":
+		return fmt.Errorf("key (%s) holds an unsupported null, leave the value empty instead", name)
+	case "yes", "Yes", "YES", "no", "No", "NO", "on", "On", "ON", "off", "Off", "OFF":
+		return fmt.Errorf("key (%s) holds an ambiguous boolean %q, use true or false, or quote it", name, value)
+	}
+	return nil
+}
+
+// parseTOML - Parse the subset of TOML config files are written in: tables,
+// dotted keys, scalars and single line arrays of scalars.
+func parseTOML(content []byte) (map[string]string, error) {
+	values := map[string]string{}
+	table := ""
+	err := eachLine(content, func(line string) error {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			return nil
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if strings.HasPrefix(line, "[[") {
+				return fmt.Errorf("arrays of tables are not supported")
+			}
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("unterminated table header")
+			}
+			name, err := tomlKey(line[1 : len(line)-1])
+			if err != nil {
+				return err
+			}
+			table = name
+			return nil
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return fmt.Errorf("expected key = value")
+		}
+		key, err := tomlKey(line[:eq])
+		if err != nil {
+			return err
+		}
+		name := joinName(table, key)
+
+		rest := strings.TrimSpace(line[eq+1:])
+		switch {
+		case strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, "'''"):
+			return fmt.Errorf("key (%s) uses unsupported multi-line strings", name)
+		case strings.HasPrefix(rest, "{"):
+			return fmt.Errorf("key (%s) uses unsupported inline tables", name)
+		case strings.HasPrefix(rest, "["):
+			items, err := parseFlowList(rest)
+			if err != nil {
+				return err
+			}
+			values[name] = strings.Join(items, ",")
+		default:
+			value, err := parseScalar(rest)
+			if err != nil {
+				return err
+			}
+			values[name] = value
+		}
+		return nil
+	})
+	return values, err
+}
+
+// tomlKey - Get the variable name of a possibly dotted and quoted TOML key.
+func tomlKey(key string) (string, error) {
+	var name string
+	for _, part := range splitOutsideQuotes(key, '.') {
+		part, err := unquote(strings.TrimSpace(part))
+		if err != nil {
+			return "", err
+		}
+		if part == "" {
+			return "", fmt.Errorf("empty key in %q", key)
+		}
+		name = joinName(name, part)
+	}
+	return name, nil
+}
+
+// parseFlowList - Parse a single line list of scalars, such as [a, "b", 3].
+func parseFlowList(s string) ([]string, error) {
+	if !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("unterminated list %q", s)
+	}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	if inner == "" {
+		return nil, nil
+	}
+
+	var items []string
+	for _, part := range splitOutsideQuotes(inner, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.HasPrefix(part, "[") || strings.HasPrefix(part, "{") {
+			return nil, fmt.Errorf("list %q holds non scalar values", s)
+		}
+		item, err := unquote(part)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// parseScalar - Parse a possibly quoted value, dropping a trailing comment
+// from unquoted values.
+func parseScalar(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		end := closingQuote(s)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", s)
+		}
+		if rest := strings.TrimSpace(s[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after quoted value", rest)
+		}
+		return unquote(s[:end+1])
+	}
+	return strings.TrimSpace(stripComment(s)), nil
+}
+
+// unquote - Remove the quotes around a value, interpreting escape sequences
+// in double quoted values.
+func unquote(s string) (string, error) {
+	if len(s) < 2 {
+		return s, nil
+	}
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"':
+		value, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value %s", s)
+		}
+		return value, nil
+	case s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	}
+	return s, nil
+}
+
+// closingQuote - Get the index of the quote closing the value s starts with,
+// or -1 when it is not closed.
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment - Remove a # comment from a line, ignoring # within quotes
+// or within a word.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitOutsideQuotes - Split s at every separator that is not quoted.
+func splitOutsideQuotes(s string, sep byte) []string {
+	var (
+		parts []string
+		quote byte
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// eachLine - Call fn with every line that is neither blank nor a comment,
+// prefixing its error with the line number.
+func eachLine(content []byte, fn func(line string) error) error {
+	return eachRawLine(content, func(raw string) error {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			return nil
+		}
+		return fn(line)
+	})
+}
+
+// eachRawLine - Call fn with every line, prefixing its error with the line
+// number.
+func eachRawLine(content []byte, fn func(line string) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		if err := fn(strings.TrimRight(scanner.Text(), "\r")); err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// joinName - Append a config key to a variable name, upper casing it and
+// replacing characters variable names cannot hold with underscores.
+func joinName(prefix, key string) string {
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, key)
+	if prefix == "" {
+		return key
+	}
+	return prefix + "_" + key
+}
+
+// validName - Whether name can be used as an environment variable name.
+func validName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	return joinName("", name) == strings.ToUpper(name)
+}
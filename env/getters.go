@@ -0,0 +1,259 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError - Error returned when an environment variable cannot be parsed
+// into the requested type.
+type ParseError struct {
+	Name  string // Name of the environment variable.
+	Value string // Value that could not be parsed, masked for secrets.
+	Type  string // Type the value was parsed into.
+	Err   error  // Why the value could not be parsed.
+}
+
+// Error - Error string naming the variable and the bad value.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("environment variable (%s) is not a valid %s: %q: %v", e.Name, e.Type, e.Value, e.Err)
+}
+
+// Get - Return an environment variable by name, or the default if it is not set.
+// If the variable cannot be read or breaks a rule, the problem is logged and the
+// default returned. Use GetString to handle the problem instead.
+func Get(name, def string, rules ...Rule) string {
+	value, err := GetString(name, def, rules...)
+	if err != nil {
+		log.Print(err)
+		return def
+	}
+	return value
+}
+
+// GetString - Return an environment variable by name, or the default if it is not set.
+// Returns the default along with the error if the variable cannot be read or breaks a rule.
+func GetString(name, def string, rules ...Rule) (string, error) {
+	value, ok, err := lookupValid(name, rules)
+	if err != nil || !ok {
+		return def, err
+	}
+	return value, nil
+}
+
+// GetInt - Return an environment variable as an int, or the default if it is not set.
+func GetInt(name string, def int, rules ...Rule) (int, error) {
+	value, ok, err := lookupValid(name, rules)
+	if err != nil || !ok {
+		return def, err
+	}
+	return parseInt(name, value)
+}
+
+// MustGetInt - Return an environment variable as an int.
+// If the variable does not exist or cannot be parsed, throw a fatal error.
+func MustGetInt(name string, rules ...Rule) int {
+	i, err := parseInt(name, MustGet(name, rules...))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return i
+}
+
+// GetBool - Return an environment variable as a bool, or the default if it is not set.
+// Accepts the values understood by strconv.ParseBool.
+func GetBool(name string, def bool, rules ...Rule) (bool, error) {
+	value, ok, err := lookupValid(name, rules)
+	if err != nil || !ok {
+		return def, err
+	}
+	return parseBool(name, value)
+}
+
+// MustGetBool - Return an environment variable as a bool.
+// If the variable does not exist or cannot be parsed, throw a fatal error.
+func MustGetBool(name string, rules ...Rule) bool {
+	b, err := parseBool(name, MustGet(name, rules...))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return b
+}
+
+// GetDuration - Return an environment variable as a duration, or the default if it is not set.
+// Accepts the values understood by time.ParseDuration, such as "1m30s".
+func GetDuration(name string, def time.Duration, rules ...Rule) (time.Duration, error) {
+	value, ok, err := lookupValid(name, rules)
+	if err != nil || !ok {
+		return def, err
+	}
+	return parseDuration(name, value)
+}
+
+// MustGetDuration - Return an environment variable as a duration.
+// If the variable does not exist or cannot be parsed, throw a fatal error.
+func MustGetDuration(name string, rules ...Rule) time.Duration {
+	d, err := parseDuration(name, MustGet(name, rules...))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return d
+}
+
+// GetURL - Return an environment variable as an absolute URL, or the default if it is not set.
+func GetURL(name string, def *url.URL, rules ...Rule) (*url.URL, error) {
+	value, ok, err := lookupValid(name, rules)
+	if err != nil || !ok {
+		return def, err
+	}
+	return parseURL(name, value)
+}
+
+// MustGetURL - Return an environment variable as an absolute URL.
+// If the variable does not exist or cannot be parsed, throw a fatal error.
+func MustGetURL(name string, rules ...Rule) *url.URL {
+	u, err := parseURL(name, MustGet(name, rules...))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return u
+}
+
+// GetStringSlice - Return a comma separated environment variable as a slice,
+// or the default if it is not set. Whitespace around each item is trimmed and
+// empty items are dropped. If the variable cannot be read or an item breaks a
+// rule, the problem is logged and the default returned. Use GetStrings to
+// handle the problem instead.
+func GetStringSlice(name string, def []string, rules ...Rule) []string {
+	items, err := GetStrings(name, def, rules...)
+	if err != nil {
+		log.Print(err)
+		return def
+	}
+	return items
+}
+
+// GetStrings - Return a comma separated environment variable as a slice, or
+// the default if it is not set, like GetStringSlice. Returns the default along
+// with the error if the variable cannot be read or an item breaks a rule.
+func GetStrings(name string, def []string, rules ...Rule) ([]string, error) {
+	items, ok, err := lookupList(name, rules)
+	if err != nil || !ok {
+		return def, err
+	}
+	return items, nil
+}
+
+// MustGetStringSlice - Return a comma separated environment variable as a slice.
+// If the variable does not exist, throw a fatal error.
+func MustGetStringSlice(name string, rules ...Rule) []string {
+	items, ok, err := lookupList(name, rules)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !ok {
+		log.Fatal(missingError(name))
+	}
+	return items
+}
+
+// lookupValid - Return a variable by name like lookup, checking its value
+// against the rules when it is set.
+func lookupValid(name string, rules []Rule) (string, bool, error) {
+	value, ok, err := lookup(name)
+	if err != nil || !ok {
+		return value, ok, err
+	}
+	if err := validate(name, value, rules); err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// lookupList - Return a comma separated variable as a slice, checking every
+// item against the rules.
+func lookupList(name string, rules []Rule) ([]string, bool, error) {
+	value, ok, err := lookup(name)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	items := splitList(value)
+	for _, item := range items {
+		if err := validate(name, item, rules); err != nil {
+			return nil, false, err
+		}
+	}
+	return items, true, nil
+}
+
+// errMissingHost - Error for URLs that are not absolute.
+var errMissingHost = errors.New("missing scheme or host")
+
+// parseError - Prepare the error for a value that cannot be parsed. Secrets
+// are masked, along with parser errors that would quote them.
+func parseError(name, value, typ string, err error) *ParseError {
+	if IsSecret(name) {
+		value = mask
+		if numErr, ok := err.(*strconv.NumError); ok {
+			err = numErr.Err
+		} else if err != errMissingHost {
+			err = errors.New("invalid syntax")
+		}
+	}
+	return &ParseError{Name: name, Value: value, Type: typ, Err: err}
+}
+
+// parseInt - Parse the value of a variable as an int.
+func parseInt(name, value string) (int, error) {
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, parseError(name, value, "int", err)
+	}
+	return i, nil
+}
+
+// parseBool - Parse the value of a variable as a bool.
+func parseBool(name, value string) (bool, error) {
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, parseError(name, value, "bool", err)
+	}
+	return b, nil
+}
+
+// parseDuration - Parse the value of a variable as a duration.
+func parseDuration(name, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, parseError(name, value, "duration", err)
+	}
+	return d, nil
+}
+
+// parseURL - Parse the value of a variable as an absolute URL.
+func parseURL(name, value string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return nil, parseError(name, value, "url", err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return nil, parseError(name, value, "url", errMissingHost)
+	}
+	return u, nil
+}
+
+// splitList - Split a comma separated value into its non-empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,287 @@
+package env
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MissingError - Error returned when a required environment variable has not
+// been set, or has been set to an empty string.
+type MissingError struct {
+	Name  string // Name of the environment variable.
+	Empty bool   // Whether the variable was set, but empty.
+}
+
+// Error - Error string naming the variable.
+func (e *MissingError) Error() string {
+	if e.Empty {
+		return fmt.Sprintf("environment variable (%s) is empty", e.Name)
+	}
+	return fmt.Sprintf("environment variable (%s) has not been set", e.Name)
+}
+
+// LoadError - Error returned when one or more fields could not be loaded.
+type LoadError struct {
+	Errors []error // Every problem found, in field order.
+}
+
+// Error - Error string listing every problem.
+func (e *LoadError) Error() string {
+	problems := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		problems[i] = err.Error()
+	}
+	return "cannot load environment: " + strings.Join(problems, "; ")
+}
+
+// Load - Fill a struct from the environment, using tags such as:
+//
+//	type Config struct {
+//	    GatewayURL *url.URL      `env:"SOA_GATEWAY_URL,required"`
+//	    Timeout    time.Duration `env:"CLIENT_TIMEOUT" default:"5s"`
+//	    Scope      string        `env:"SERVICE_SCOPE" validate:"oneof=public|internal"`
+//	    Database   DBConfig      `prefix:"DB_"`
+//	}
+//
+// The validate tag holds comma separated rules checked against the value,
+// whether it came from the environment or the default tag: oneof=a|b,
+// range=min:max (either bound may be left out, and durations are accepted for
+// time.Duration fields), url or url=https|http, semver, and match=expression,
+// which has to come last as the expression may hold commas.
+//
+// The desc tag documents the variable, which Load declares as DeclareStruct
+// does.
+//
+// Struct fields tagged with prefix are loaded recursively, with the prefix
+// prepended to the names of their variables. Fields that already hold a
+// non-zero value are left untouched, so values supplied from code take
+// precedence over the environment, and Check does not report them missing.
+//
+// Every missing or unparsable field is reported in a single *LoadError.
+func Load(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot load environment into %T: expected a pointer to a struct", dst)
+	}
+
+	declareStruct(v.Elem().Type(), "")
+
+	loadErr := &LoadError{}
+	loadStruct(v.Elem(), "", loadErr)
+	if len(loadErr.Errors) > 0 {
+		return loadErr
+	}
+	return nil
+}
+
+// fieldTag - The parsed env tag of a struct field.
+type fieldTag struct {
+	name     string
+	required bool
+	secret   bool
+}
+
+// parseFieldTag - Parse an env tag such as "NAME,required,secret".
+func parseFieldTag(tag string) fieldTag {
+	parts := strings.Split(tag, ",")
+	ft := fieldTag{name: strings.TrimSpace(parts[0])}
+	for _, opt := range parts[1:] {
+		switch strings.TrimSpace(opt) {
+		case "required":
+			ft.required = true
+		case "secret":
+			ft.secret = true
+		}
+	}
+	return ft
+}
+
+// loadStruct - Load every tagged field of a struct.
+func loadStruct(v reflect.Value, prefix string, loadErr *LoadError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag, tagged := field.Tag.Lookup("env")
+		nestedPrefix, nested := field.Tag.Lookup("prefix")
+		if !tagged && (nested || field.Anonymous) {
+			loadNested(fv, prefix+nestedPrefix, loadErr)
+			continue
+		}
+		if !tagged {
+			continue
+		}
+
+		ft := parseFieldTag(tag)
+		if ft.name == "" {
+			continue
+		}
+		name := prefix + ft.name
+		if ft.secret || fv.Type() == secretType {
+			MarkSecret(name)
+		}
+		if !isZero(fv) {
+			record(name, fmt.Sprint(fv.Interface()), SourceCode, true)
+			continue
+		}
+
+		value, ok, err := lookup(name)
+		if err != nil {
+			loadErr.Errors = append(loadErr.Errors, err)
+			continue
+		}
+		if !ok {
+			if value, ok = field.Tag.Lookup("default"); ok {
+				record(name, value, SourceDefault, true)
+			}
+		}
+		if !ok {
+			if ft.required {
+				loadErr.Errors = append(loadErr.Errors, missingError(name))
+			}
+			continue
+		}
+
+		if err := validateField(fv, field, name, value); err != nil {
+			loadErr.Errors = append(loadErr.Errors, err)
+			continue
+		}
+		if err := setField(fv, name, value); err != nil {
+			loadErr.Errors = append(loadErr.Errors, err)
+		}
+	}
+}
+
+// loadNested - Load a nested struct, or a pointer to one.
+func loadNested(v reflect.Value, prefix string, loadErr *LoadError) {
+	switch {
+	case v.Kind() == reflect.Struct:
+		loadStruct(v, prefix, loadErr)
+	case v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Struct:
+		if v.IsNil() {
+			if !v.CanSet() {
+				return
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		loadStruct(v.Elem(), prefix, loadErr)
+	}
+}
+
+// validateField - Check the value of a field against the rules of its
+// validate tag. Lists are checked item by item.
+func validateField(v reflect.Value, field reflect.StructField, name, value string) error {
+	tag, ok := field.Tag.Lookup("validate")
+	if !ok {
+		return nil
+	}
+	rules, err := parseRules(tag, v.Type())
+	if err != nil {
+		return fmt.Errorf("field (%s) has an invalid validate tag: %v", field.Name, err)
+	}
+
+	if v.Kind() == reflect.Slice {
+		for _, item := range splitList(value) {
+			if err := validate(name, item, rules); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return validate(name, value, rules)
+}
+
+// missingError - Prepare the error for a variable that has not been provided.
+func missingError(name string) error {
+	_, set := os.LookupEnv(name)
+	return &MissingError{Name: name, Empty: set}
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	urlType      = reflect.TypeOf(url.URL{})
+	secretType   = reflect.TypeOf(Secret(""))
+)
+
+// setField - Parse a value into a field based on its type.
+func setField(v reflect.Value, name, value string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := parseDuration(name, value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case v.Type() == urlType:
+		u, err := parseURL(name, value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(*u))
+		return nil
+	case v.Type() == reflect.PtrTo(urlType):
+		u, err := parseURL(name, value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(u))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := parseBool(name, value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(strings.TrimSpace(value), 10, v.Type().Bits())
+		if err != nil {
+			return parseError(name, value, v.Type().String(), err)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(strings.TrimSpace(value), 10, v.Type().Bits())
+		if err != nil {
+			return parseError(name, value, v.Type().String(), err)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), v.Type().Bits())
+		if err != nil {
+			return parseError(name, value, v.Type().String(), err)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("environment variable (%s) cannot be loaded into unsupported type %s", name, v.Type())
+		}
+		items := splitList(value)
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			slice.Index(i).SetString(item)
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("environment variable (%s) cannot be loaded into unsupported type %s", name, v.Type())
+	}
+	return nil
+}
+
+// isZero - Whether a field holds its zero value.
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
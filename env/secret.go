@@ -0,0 +1,139 @@
+package env
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FileSuffix - Suffix of a variable naming a file to read the value of
+// another variable from, so NAME can be provided as NAME_FILE instead.
+const FileSuffix = "_FILE"
+
+// mask - What secret values are replaced with when displayed.
+const mask = "********"
+
+// Secret - A value that must not be displayed. It is masked when formatted
+// or encoded, and has to be converted back to a string to be used.
+type Secret string
+
+// String - Get the masked value.
+func (s Secret) String() string {
+	return mask
+}
+
+// GoString - Get the masked value for the %#v verb.
+func (s Secret) GoString() string {
+	return mask
+}
+
+// MarshalJSON - Encode the masked value.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + mask + `"`), nil
+}
+
+var (
+	secretsMu sync.RWMutex
+	secrets   = map[string]bool{}
+
+	secretFilesMu sync.Mutex
+	secretFiles   = map[string]string{} // Files variables were read from, by name.
+)
+
+// MarkSecret - Mark a variable as holding a secret, so its value is masked
+// wherever env displays it.
+func MarkSecret(name string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	secrets[name] = true
+}
+
+// IsSecret - Whether a variable holds a secret. Variables read from a file,
+// requested with GetSecret or tagged with the secret option are secrets.
+func IsSecret(name string) bool {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	return secrets[name]
+}
+
+// Mask - Get the value of a variable as it may be displayed.
+func Mask(name, value string) string {
+	if IsSecret(name) {
+		return mask
+	}
+	return value
+}
+
+// GetSecret - Return an environment variable as a secret, or the default if it is not set.
+func GetSecret(name string, def Secret, rules ...Rule) (Secret, error) {
+	MarkSecret(name)
+	value, ok, err := lookupValid(name, rules)
+	if err != nil || !ok {
+		return def, err
+	}
+	return Secret(value), nil
+}
+
+// lookup - Return a variable by name, treating empty values as unset, and
+// record how it was resolved for Variables.
+func lookup(name string) (string, bool, error) {
+	value, source, ok, err := resolve(name)
+	if err == nil {
+		record(name, value, source, ok)
+	}
+	return value, ok, err
+}
+
+// resolve - Return a variable by name along with its source, treating empty
+// values as unset. Variables are read from the process environment first and
+// then from the loaded config files. When NAME_FILE is set by a source with a
+// higher precedence than NAME, the value is read from the file it points at.
+func resolve(name string) (value, source string, ok bool, err error) {
+	value, source, rank, ok := find(name)
+	path, _, fileRank, fileOK := find(name + FileSuffix)
+
+	switch {
+	case ok && fileOK && rank == fileRank:
+		return "", "", false, fmt.Errorf("environment variables (%s) and (%s%s) are both set", name, name, FileSuffix)
+	case fileOK && fileRank > rank:
+		watchSecretFile(name, path)
+		value, err := readFile(name, path)
+		if err != nil {
+			return "", "", false, err
+		}
+		MarkSecret(name)
+		return value, path, value != "", nil
+	}
+	return value, source, ok, nil
+}
+
+// watchSecretFile - Record the file a variable was read from, so Store.Watch
+// reloads when it is rotated.
+func watchSecretFile(name, path string) {
+	secretFilesMu.Lock()
+	defer secretFilesMu.Unlock()
+	secretFiles[name] = path
+}
+
+// readFile - Read the value of a variable from a file, refusing files that
+// anyone could have written to.
+func readFile(name, path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("environment variable (%s%s) points at an unreadable file: %v", name, FileSuffix, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("environment variable (%s%s) does not point at a regular file: %s", name, FileSuffix, path)
+	}
+	if info.Mode().Perm()&0002 != 0 {
+		return "", fmt.Errorf("environment variable (%s%s) points at a world writable file: %s", name, FileSuffix, path)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("environment variable (%s%s) points at an unreadable file: %v", name, FileSuffix, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,182 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Validator - Implemented by configs that check their own values. A Store
+// refuses to swap in a config failing validation.
+type Validator interface {
+	Validate() error
+}
+
+// Store - Holds a config loaded from the environment and config files, and
+// swaps in a freshly loaded copy when its sources change.
+type Store struct {
+	base reflect.Value // The config as supplied, holding the values set from code.
+
+	reloadMu sync.Mutex // Serialises reloads.
+
+	mu          sync.RWMutex
+	current     reflect.Value
+	subscribers []reflect.Value
+}
+
+// NewStore - Load a config into a store. The config must be a pointer to a
+// struct tagged for Load. Values it already holds are kept across reloads,
+// and it is validated when it implements Validator.
+func NewStore(cfg interface{}) (*Store, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot store config %T: expected a pointer to a struct", cfg)
+	}
+
+	s := &Store{base: reflect.New(v.Type().Elem())}
+	s.base.Elem().Set(v.Elem())
+
+	current, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	s.current = current
+	return s, nil
+}
+
+// Get - Get the current config, a pointer of the type passed to NewStore.
+// Reloads replace the config rather than modify it, so it must not be
+// modified either.
+func (s *Store) Get() interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current.Interface()
+}
+
+// Subscribe - Register a function to call whenever a reload changes the
+// config. It must accept pointers of the type passed to NewStore, either as
+// func(cfg *Config) or as func(previous, cfg *Config).
+func (s *Store) Subscribe(fn interface{}) error {
+	v := reflect.ValueOf(fn)
+	valid := v.Kind() == reflect.Func && v.Type().NumOut() == 0 && (v.Type().NumIn() == 1 || v.Type().NumIn() == 2)
+	for i := 0; valid && i < v.Type().NumIn(); i++ {
+		valid = v.Type().In(i) == s.base.Type()
+	}
+	if !valid {
+		return fmt.Errorf("cannot subscribe %T: expected func(%s) or func(previous, cfg %s)", fn, s.base.Type(), s.base.Type())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, v)
+	return nil
+}
+
+// Reload - Read the config files and the environment again, and swap in the
+// new config when it is valid. Subscribers are notified in the order they
+// subscribed when the config changed. When the new config is invalid, the
+// previous config and config file values are kept.
+func (s *Store) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	restore, err := reloadFiles()
+	if err != nil {
+		return err
+	}
+	next, err := s.load()
+	if err != nil {
+		restore()
+		return err
+	}
+
+	s.mu.Lock()
+	previous := s.current
+	changed := !reflect.DeepEqual(previous.Interface(), next.Interface())
+	if changed {
+		s.current = next
+	}
+	subscribers := s.subscribers
+	s.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	for _, fn := range subscribers {
+		if fn.Type().NumIn() == 2 {
+			fn.Call([]reflect.Value{previous, next})
+		} else {
+			fn.Call([]reflect.Value{next})
+		}
+	}
+	return nil
+}
+
+// Watch - Reload the store whenever the process receives SIGHUP and, when the
+// interval is positive, whenever a config file loaded with LoadFiles or a file
+// a variable was read from through NAME_FILE changes, checking every interval.
+// A changed file is only reloaded once it has stayed the same for a whole
+// interval, so files being written are not read half way. Blocks until the
+// context is done. Failed reloads are logged and leave the previous config in
+// place.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	stamps := fileStamps()
+	var changed map[string]fileStamp // Stamps seen on the previous tick, when they had changed.
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+		case <-tick:
+			current := fileStamps()
+			if reflect.DeepEqual(stamps, current) {
+				changed = nil
+				continue
+			}
+			if !reflect.DeepEqual(changed, current) {
+				changed = current
+				continue
+			}
+		}
+
+		changed = nil
+		stamps = fileStamps()
+		if err := s.Reload(); err != nil {
+			log.Printf("cannot reload config: %v", err)
+		}
+	}
+}
+
+// load - Load a fresh copy of the config and validate it.
+func (s *Store) load() (reflect.Value, error) {
+	v := reflect.New(s.base.Type().Elem())
+	v.Elem().Set(s.base.Elem())
+
+	cfg := v.Interface()
+	if err := Load(cfg); err != nil {
+		return reflect.Value{}, err
+	}
+	if validator, ok := cfg.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid config: %v", err)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,233 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule - Checks the value of a variable, returning why it is invalid. Every
+// getter accepts rules, which are checked when the variable is set:
+//
+//	scope, err := env.GetString("SERVICE_SCOPE", "internal", env.OneOf("public", "internal"))
+type Rule func(value string) error
+
+// ValidationError - Error returned when the value of a variable breaks a rule.
+type ValidationError struct {
+	Name  string // Name of the environment variable.
+	Value string // Value that broke the rule, masked for secrets.
+	Err   error  // Why the value is invalid.
+}
+
+// Error - Error string naming the variable and the bad value.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("environment variable (%s) is invalid: %q: %v", e.Name, e.Value, e.Err)
+}
+
+// Collect - Combine the errors returned by several getters into a single
+// *LoadError, so every problem can be reported at once. Returns nil when
+// every error is nil.
+func Collect(errs ...error) error {
+	loadErr := &LoadError{}
+	for _, err := range errs {
+		if err != nil {
+			loadErr.Errors = append(loadErr.Errors, err)
+		}
+	}
+	if len(loadErr.Errors) == 0 {
+		return nil
+	}
+	return loadErr
+}
+
+// OneOf - Require the value to be one of the provided values.
+func OneOf(values ...string) Rule {
+	return func(value string) error {
+		for _, v := range values {
+			if value == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(values, "|"))
+	}
+}
+
+// Range - Require the value to be a number between min and max, inclusive.
+func Range(min, max float64) Rule {
+	return func(value string) error {
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		if f < min || f > max {
+			return fmt.Errorf("must be between %v and %v", min, max)
+		}
+		return nil
+	}
+}
+
+// DurationRange - Require the value to be a duration between min and max,
+// inclusive.
+func DurationRange(min, max time.Duration) Rule {
+	return func(value string) error {
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return errors.New("must be a duration")
+		}
+		if d < min || d > max {
+			return fmt.Errorf("must be between %s and %s", min, max)
+		}
+		return nil
+	}
+}
+
+// Match - Require the whole value to match a regular expression. Panics when
+// the expression does not compile, like regexp.MustCompile.
+func Match(pattern string) Rule {
+	re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("must match %s", pattern)
+		}
+		return nil
+	}
+}
+
+// URL - Require the value to be an absolute URL, using one of the provided
+// schemes when any are provided.
+func URL(schemes ...string) Rule {
+	return func(value string) error {
+		u, err := url.Parse(strings.TrimSpace(value))
+		if err != nil || !u.IsAbs() || u.Host == "" {
+			return errors.New("must be an absolute URL")
+		}
+		if len(schemes) == 0 {
+			return nil
+		}
+		for _, scheme := range schemes {
+			if strings.EqualFold(u.Scheme, scheme) {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be a %s URL", strings.Join(schemes, "|"))
+	}
+}
+
+// semVerPattern - Semantic version as defined by semver.org.
+var semVerPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// SemVer - Require the value to be a semantic version, such as 1.4.0-rc.1.
+func SemVer() Rule {
+	return func(value string) error {
+		if !semVerPattern.MatchString(value) {
+			return errors.New("must be a semantic version")
+		}
+		return nil
+	}
+}
+
+// validate - Check a value against every rule, returning the first problem.
+func validate(name, value string, rules []Rule) error {
+	for _, rule := range rules {
+		if err := rule(value); err != nil {
+			return &ValidationError{Name: name, Value: Mask(name, value), Err: err}
+		}
+	}
+	return nil
+}
+
+// parseRules - Parse a validate tag such as "oneof=public|internal,semver".
+// Rules are separated by commas, except for match, which takes the rest of
+// the tag as its expression.
+func parseRules(tag string, t reflect.Type) ([]Rule, error) {
+	var rules []Rule
+	for tag = strings.TrimSpace(tag); tag != ""; tag = strings.TrimSpace(tag) {
+		var spec string
+		if strings.HasPrefix(tag, "match=") {
+			spec, tag = tag, ""
+		} else if comma := strings.Index(tag, ","); comma >= 0 {
+			spec, tag = tag[:comma], tag[comma+1:]
+		} else {
+			spec, tag = tag, ""
+		}
+
+		kind, arg := spec, ""
+		if eq := strings.Index(spec, "="); eq >= 0 {
+			kind, arg = spec[:eq], spec[eq+1:]
+		}
+		rule, err := parseRule(strings.TrimSpace(kind), arg, t)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseRule - Parse a single rule of a validate tag.
+func parseRule(kind, arg string, t reflect.Type) (Rule, error) {
+	switch kind {
+	case "oneof":
+		if arg == "" {
+			return nil, errors.New("oneof needs values, such as oneof=a|b")
+		}
+		return OneOf(strings.Split(arg, "|")...), nil
+	case "range":
+		bounds := strings.SplitN(arg, ":", 2)
+		if len(bounds) != 2 {
+			return nil, errors.New("range needs bounds, such as range=1:10")
+		}
+		if t == durationType {
+			min, minErr := parseDurationBound(bounds[0], math.MinInt64)
+			max, maxErr := parseDurationBound(bounds[1], math.MaxInt64)
+			if minErr != nil || maxErr != nil {
+				return nil, fmt.Errorf("invalid duration range %q", arg)
+			}
+			return DurationRange(min, max), nil
+		}
+		min, minErr := parseBound(bounds[0], -math.MaxFloat64)
+		max, maxErr := parseBound(bounds[1], math.MaxFloat64)
+		if minErr != nil || maxErr != nil {
+			return nil, fmt.Errorf("invalid range %q", arg)
+		}
+		return Range(min, max), nil
+	case "match":
+		if _, err := regexp.Compile(arg); err != nil {
+			return nil, fmt.Errorf("invalid match expression: %v", err)
+		}
+		return Match(arg), nil
+	case "url":
+		if arg == "" {
+			return URL(), nil
+		}
+		return URL(strings.Split(arg, "|")...), nil
+	case "semver":
+		return SemVer(), nil
+	}
+	return nil, fmt.Errorf("unknown rule %q", kind)
+}
+
+// parseBound - Parse a bound of a range tag, which is unbounded when empty.
+func parseBound(s string, unbounded float64) (float64, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return unbounded, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseDurationBound - Parse a bound of a duration range tag, which is
+// unbounded when empty.
+func parseDurationBound(s string, unbounded time.Duration) (time.Duration, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return unbounded, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,62 @@
+package env
+
+import (
+	"sort"
+	"sync"
+)
+
+// SourceDefault - Source of values taken from the default tag of a field
+// filled by Load.
+const SourceDefault = "default"
+
+// SourceCode - Source of values supplied from code, held by fields before
+// Load fills them.
+const SourceCode = "code"
+
+// Variable - A variable read through env, as it was last resolved.
+type Variable struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`            // Value, masked for secrets.
+	Source string `json:"source,omitempty"` // Where the value came from, empty when unset.
+	Set    bool   `json:"set"`              // Whether a value was found.
+	Secret bool   `json:"secret"`           // Whether the variable holds a secret.
+}
+
+var (
+	variablesMu sync.RWMutex
+	variables   = map[string]Variable{}
+)
+
+// Variables - Get every variable read through env so far, sorted by name,
+// with the values of secrets masked.
+func Variables() []Variable {
+	variablesMu.RLock()
+	defer variablesMu.RUnlock()
+
+	list := make([]Variable, 0, len(variables))
+	for _, v := range variables {
+		v.Secret = IsSecret(v.Name)
+		if v.Set {
+			v.Value = Mask(v.Name, v.Value)
+		}
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// record - Remember how a variable was resolved.
+func record(name, value, source string, set bool) {
+	variablesMu.Lock()
+	defer variablesMu.Unlock()
+	variables[name] = Variable{Name: name, Value: value, Source: source, Set: set}
+}
+
+// supplied - Whether the value of a variable was last supplied from code.
+func supplied(name string) bool {
+	variablesMu.RLock()
+	defer variablesMu.RUnlock()
+	return variables[name].Source == SourceCode
+}
@@ -0,0 +1,90 @@
+package microservicecore
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Middleware - Wraps a route handler, for example to check authentication.
+type Middleware func(http.HandlerFunc) http.HandlerFunc
+
+// groupEntry - A route or nested group, in the order it was added to a group.
+type groupEntry struct {
+	route *Route
+	group *Group
+}
+
+// Group - Builds routes sharing a path prefix and a middleware stack.
+//
+// Middleware is applied in the order it is provided, with the middleware of
+// an outer group running before that of the groups nested inside it.
+type Group struct {
+	prefix     string
+	middleware []Middleware
+	entries    []groupEntry
+}
+
+// NewGroup - Prepare a group of routes under the provided path prefix.
+func NewGroup(prefix string, middleware ...Middleware) *Group {
+	return &Group{
+		prefix:     prefix,
+		middleware: middleware,
+	}
+}
+
+// Group - Prepare a group nested under this one, extending its prefix and
+// middleware stack.
+func (g *Group) Group(prefix string, middleware ...Middleware) *Group {
+	nested := NewGroup(prefix, middleware...)
+	g.entries = append(g.entries, groupEntry{group: nested})
+	return nested
+}
+
+// Handle - Add routes to the group. Their paths are relative to the group prefix.
+func (g *Group) Handle(routes ...Route) *Group {
+	for i := range routes {
+		g.entries = append(g.entries, groupEntry{route: &routes[i]})
+	}
+	return g
+}
+
+// Routes - Get every route in the group and its nested groups, with the
+// prefixes joined and the middleware applied, ready to pass to a Router.
+func (g *Group) Routes() []Route {
+	var routes []Route
+	for _, entry := range g.entries {
+		if entry.group != nil {
+			for _, route := range entry.group.Routes() {
+				routes = append(routes, g.apply(route))
+			}
+			continue
+		}
+		routes = append(routes, g.apply(*entry.route))
+	}
+	return routes
+}
+
+// apply - Prefix a route and wrap its handler in the group middleware.
+func (g *Group) apply(route Route) Route {
+	route.Path = joinPath(g.prefix, route.Path)
+
+	handler := http.HandlerFunc(route.Handler)
+	for i := len(g.middleware) - 1; i >= 0; i-- {
+		handler = g.middleware[i](handler)
+	}
+	route.Handler = handler
+	return route
+}
+
+// joinPath - Join a prefix and a path with exactly one slash between them.
+func joinPath(prefix, path string) string {
+	prefix = "/" + strings.Trim(prefix, "/")
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return prefix
+	}
+	if prefix == "/" {
+		return prefix + path
+	}
+	return prefix + "/" + path
+}
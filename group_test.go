@@ -0,0 +1,93 @@
+package microservicecore
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// tracingMiddleware - Middleware recording when it runs.
+func tracingMiddleware(name string, trace *[]string) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			*trace = append(*trace, name)
+			next(w, r)
+		}
+	}
+}
+
+func TestGroup_Routes(t *testing.T) {
+	var trace []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		trace = append(trace, "handler")
+		fmt.Fprint(w, PathParams(r).String("id"))
+	}
+
+	v1 := NewGroup("/v1", tracingMiddleware("v1", &trace))
+	v1.Handle(Route{Path: "/products/{id}", Method: http.MethodGet, Handler: handler})
+	admin := v1.Group("admin/", tracingMiddleware("auth", &trace), tracingMiddleware("audit", &trace))
+	admin.Handle(
+		Route{Path: "/", Method: http.MethodGet, Handler: handler},
+		Route{Path: "/products/{id}", Method: http.MethodDelete, Handler: handler},
+	)
+
+	routes := v1.Routes()
+	var paths []string
+	for _, route := range routes {
+		paths = append(paths, route.Method+" "+route.Path)
+	}
+	expectedPaths := []string{
+		"GET /v1/products/{id}",
+		"GET /v1/admin",
+		"DELETE /v1/admin/products/{id}",
+	}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("expected %v got %v", expectedPaths, paths)
+	}
+
+	info := &MicroserviceInfo{}
+	router, err := NewRouter(info, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Endpoints) != len(expectedPaths) {
+		t.Errorf("expected %d endpoints got %d", len(expectedPaths), len(info.Endpoints))
+	}
+
+	tt := []struct {
+		name          string
+		method        string
+		path          string
+		expectedTrace []string
+	}{
+		{
+			name:          "outer group",
+			method:        http.MethodGet,
+			path:          "/v1/products/42",
+			expectedTrace: []string{"v1", "handler"},
+		},
+		{
+			name:          "nested group",
+			method:        http.MethodDelete,
+			path:          "/v1/admin/products/42",
+			expectedTrace: []string{"v1", "auth", "audit", "handler"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			trace = nil
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+
+			if rec.Body.String() != "42" {
+				t.Errorf("expected body 42 got %q", rec.Body.String())
+			}
+			if !reflect.DeepEqual(trace, tc.expectedTrace) {
+				t.Errorf("expected trace %v got %v", tc.expectedTrace, trace)
+			}
+		})
+	}
+}
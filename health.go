@@ -0,0 +1,264 @@
+package microservicecore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/LUSHDigital/microservice-core-golang/response"
+	"github.com/LUSHDigital/microservice-core-golang/transport"
+)
+
+const (
+	// LivenessPath - Path the liveness route is served on.
+	LivenessPath = "/healthz"
+
+	// ReadinessPath - Path the readiness route is served on.
+	ReadinessPath = "/readyz"
+
+	// DefaultHealthCheckTimeout - Timeout applied to checks registered without one.
+	DefaultHealthCheckTimeout = 5 * time.Second
+)
+
+// Health check statuses.
+const (
+	HealthStatusPass = "pass"
+	HealthStatusFail = "fail"
+)
+
+// Checker - Checks the health of a single dependency.
+type Checker interface {
+	// Check - Return an error if the dependency is unhealthy.
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc - Adapter to allow the use of ordinary functions as checkers.
+type CheckerFunc func(ctx context.Context) error
+
+// Check - Call f(ctx).
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Pinger - Anything that can be pinged, such as *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingChecker - Prepare a checker that pings a database connection.
+func PingChecker(p Pinger) Checker {
+	return CheckerFunc(p.PingContext)
+}
+
+// ServiceChecker - Prepare a checker that calls a downstream service and
+// expects a non-error HTTP status code back.
+func ServiceChecker(t transport.Transport, request *transport.Request) Checker {
+	// A transport holds the state of its current request, so calls through
+	// the same transport must not overlap.
+	var mu sync.Mutex
+	return CheckerFunc(func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err := t.Dial(request); err != nil {
+			return fmt.Errorf("cannot dial %s: %v", t.GetName(), err)
+		}
+		resp, err := t.Call()
+		if err != nil {
+			return fmt.Errorf("cannot call %s: %v", t.GetName(), err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("%s responded with status %d", t.GetName(), resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// HealthCheckResult - The outcome of a single named check.
+type HealthCheckResult struct {
+	Name     string  `json:"name"`            // Name the check was registered with.
+	Status   string  `json:"status"`          // Can be 'pass' or 'fail'.
+	Duration float64 `json:"duration_ms"`     // How long the check took in milliseconds.
+	Error    string  `json:"error,omitempty"` // Why the check failed (if it did).
+}
+
+// HealthReport - The combined outcome of a set of checks.
+type HealthReport struct {
+	Status string              `json:"status"` // Can be 'pass' or 'fail'.
+	Checks []HealthCheckResult `json:"checks"` // Per check breakdown.
+}
+
+// healthCheck - A registered checker.
+type healthCheck struct {
+	name    string
+	timeout time.Duration
+	checker Checker
+}
+
+// run - Run the check, giving up once the timeout has elapsed.
+func (c *healthCheck) run(ctx context.Context) HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- c.checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	result := HealthCheckResult{
+		Name:     c.name,
+		Status:   HealthStatusPass,
+		Duration: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		result.Status = HealthStatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Health - Registry of liveness and readiness checks.
+type Health struct {
+	mu        sync.RWMutex
+	liveness  []*healthCheck
+	readiness []*healthCheck
+	draining  bool
+}
+
+// NewHealth - Prepare a new, empty health registry.
+func NewHealth() *Health {
+	return &Health{}
+}
+
+// AddLivenessCheck - Register a check that decides whether the process should
+// be restarted. A zero timeout means DefaultHealthCheckTimeout.
+func (h *Health) AddLivenessCheck(name string, timeout time.Duration, checker Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness = append(h.liveness, newHealthCheck(name, timeout, checker))
+}
+
+// AddReadinessCheck - Register a check that decides whether the process should
+// receive traffic. A zero timeout means DefaultHealthCheckTimeout.
+func (h *Health) AddReadinessCheck(name string, timeout time.Duration, checker Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness = append(h.readiness, newHealthCheck(name, timeout, checker))
+}
+
+// Liveness - Run every liveness check and report the outcome.
+func (h *Health) Liveness(ctx context.Context) *HealthReport {
+	h.mu.RLock()
+	checks := h.liveness
+	h.mu.RUnlock()
+	return runChecks(ctx, checks)
+}
+
+// Drain - Fail every readiness report from now on, so traffic is moved away
+// from the process before it shuts down.
+func (h *Health) Drain() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.draining = true
+}
+
+// Readiness - Run every readiness check and report the outcome.
+func (h *Health) Readiness(ctx context.Context) *HealthReport {
+	h.mu.RLock()
+	checks, draining := h.readiness, h.draining
+	h.mu.RUnlock()
+
+	report := runChecks(ctx, checks)
+	if draining {
+		report.Status = HealthStatusFail
+		report.Checks = append(report.Checks, HealthCheckResult{
+			Name:   "shutdown",
+			Status: HealthStatusFail,
+			Error:  "shutting down",
+		})
+	}
+	return report
+}
+
+// LivenessRoute - Get the route serving the liveness report.
+func (h *Health) LivenessRoute() Route {
+	return Route{
+		Path:   LivenessPath,
+		Method: http.MethodGet,
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			healthResponse(h.Liveness(r.Context())).WriteTo(w)
+		},
+	}
+}
+
+// ReadinessRoute - Get the route serving the readiness report.
+func (h *Health) ReadinessRoute() Route {
+	return Route{
+		Path:   ReadinessPath,
+		Method: http.MethodGet,
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			healthResponse(h.Readiness(r.Context())).WriteTo(w)
+		},
+	}
+}
+
+// newHealthCheck - Prepare a registered checker, applying the default timeout.
+func newHealthCheck(name string, timeout time.Duration, checker Checker) *healthCheck {
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	return &healthCheck{
+		name:    name,
+		timeout: timeout,
+		checker: checker,
+	}
+}
+
+// runChecks - Run a set of checks concurrently and combine their results.
+func runChecks(ctx context.Context, checks []*healthCheck) *HealthReport {
+	report := &HealthReport{
+		Status: HealthStatusPass,
+		Checks: make([]HealthCheckResult, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check *healthCheck) {
+			defer wg.Done()
+			report.Checks[i] = check.run(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != HealthStatusPass {
+			report.Status = HealthStatusFail
+		}
+	}
+	return report
+}
+
+// healthResponse - Wrap a report in the standard response envelope.
+func healthResponse(report *HealthReport) *response.Response {
+	data := &response.Data{
+		Type:    "health",
+		Content: report,
+	}
+	if report.Status != HealthStatusPass {
+		return response.New(http.StatusServiceUnavailable, "one or more health checks failed", data)
+	}
+	return response.New(http.StatusOK, "", data)
+}
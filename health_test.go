@@ -0,0 +1,155 @@
+package microservicecore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LUSHDigital/microservice-core-golang/response"
+	"github.com/LUSHDigital/microservice-core-golang/transport"
+)
+
+func TestHealth_Readiness(t *testing.T) {
+	passing := CheckerFunc(func(ctx context.Context) error { return nil })
+	failing := CheckerFunc(func(ctx context.Context) error { return errors.New("broken") })
+	hanging := CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+
+	tt := []struct {
+		name           string
+		checks         map[string]Checker
+		expectedStatus string
+		expectedCode   int
+	}{
+		{
+			name:           "no checks",
+			expectedStatus: HealthStatusPass,
+			expectedCode:   http.StatusOK,
+		},
+		{
+			name:           "passing check",
+			checks:         map[string]Checker{"db": passing},
+			expectedStatus: HealthStatusPass,
+			expectedCode:   http.StatusOK,
+		},
+		{
+			name:           "one failing check",
+			checks:         map[string]Checker{"db": passing, "cache": failing},
+			expectedStatus: HealthStatusFail,
+			expectedCode:   http.StatusServiceUnavailable,
+		},
+		{
+			name:           "check times out",
+			checks:         map[string]Checker{"slow": hanging},
+			expectedStatus: HealthStatusFail,
+			expectedCode:   http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			health := NewHealth()
+			for name, checker := range tc.checks {
+				health.AddReadinessCheck(name, 10*time.Millisecond, checker)
+			}
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, ReadinessPath, nil)
+			health.ReadinessRoute().Handler(rec, req)
+
+			if rec.Code != tc.expectedCode {
+				t.Errorf("expected code %d got %d", tc.expectedCode, rec.Code)
+			}
+
+			var resp response.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			var report HealthReport
+			if err := resp.ExtractData("health", &report); err != nil {
+				t.Fatal(err)
+			}
+			if report.Status != tc.expectedStatus {
+				t.Errorf("expected status %s got %s", tc.expectedStatus, report.Status)
+			}
+			if len(report.Checks) != len(tc.checks) {
+				t.Errorf("expected %d checks got %d", len(tc.checks), len(report.Checks))
+			}
+		})
+	}
+}
+
+func TestHealth_LivenessIgnoresReadinessChecks(t *testing.T) {
+	health := NewHealth()
+	health.AddReadinessCheck("db", 0, CheckerFunc(func(ctx context.Context) error {
+		return errors.New("broken")
+	}))
+
+	report := health.Liveness(context.Background())
+	if report.Status != HealthStatusPass {
+		t.Errorf("expected status %s got %s", HealthStatusPass, report.Status)
+	}
+}
+
+func TestServiceChecker(t *testing.T) {
+	tt := []struct {
+		name        string
+		statusCode  int
+		expectedErr bool
+	}{
+		{
+			name:       "healthy service",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:        "unhealthy service",
+			statusCode:  http.StatusInternalServerError,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+
+			checker := ServiceChecker(&stubTransport{url: server.URL}, &transport.Request{Method: http.MethodGet})
+			err := checker.Check(context.Background())
+			if tc.expectedErr && err == nil {
+				t.Error("expected an error")
+			}
+			if !tc.expectedErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// stubTransport - Transport calling a fixed URL.
+type stubTransport struct {
+	url     string
+	request *http.Request
+}
+
+func (s *stubTransport) Call() (*http.Response, error) {
+	return http.DefaultClient.Do(s.request)
+}
+
+func (s *stubTransport) Dial(request *transport.Request) error {
+	var err error
+	s.request, err = http.NewRequest(request.Method, s.url+"/"+request.Resource, nil)
+	return err
+}
+
+func (s *stubTransport) GetName() string {
+	return "stub"
+}
@@ -1,17 +1,26 @@
 package microservicecore
 
 import (
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
+
 	"github.com/LUSHDigital/microservice-core-golang/env"
+	"github.com/LUSHDigital/microservice-core-golang/response"
 )
 
+// InfoPath - Path the info route is served on.
+const InfoPath = "/info"
+
 // MicroserviceInfo - Represents information about this microservice.
 type MicroserviceInfo struct {
-	ServiceName    string  `json:"service_name"`
-	ServiceType    string  `json:"service_type"`
-	ServiceScope   string  `json:"service_scope"`
-	ServiceVersion string  `json:"service_version"`
-	Endpoints      []Route `json:"endpoints"`
+	ServiceName    string     `json:"service_name" env:"SERVICE_NAME,required" desc:"Name of the service."`
+	ServiceType    string     `json:"service_type" env:"SERVICE_TYPE,required" desc:"Type of the service, such as service or aggregator."`
+	ServiceScope   string     `json:"service_scope" env:"SERVICE_SCOPE,required" desc:"Scope the service is exposed to."`
+	ServiceVersion string     `json:"service_version" env:"SERVICE_VERSION,required" desc:"Version of the service."`
+	Endpoints      []Route    `json:"endpoints"`       // Filled in by the Router the info is attached to.
+	Build          *BuildInfo `json:"build,omitempty"` // Filled in when the info is served.
 }
 
 // Route defines an HTTP route
@@ -19,17 +28,150 @@ type Route struct {
 	Path    string                                   `json:"uri"`
 	Method  string                                   `json:"method"`
 	Handler func(http.ResponseWriter, *http.Request) `json:"-"`
+
+	// Major API versions the route serves, selected through the service
+	// version header. A route without versions serves any version.
+	Versions []int `json:"versions,omitempty"`
+
+	// Optional metadata used to document the route.
+	Summary   string       `json:"-"` // Short description of what the route does.
+	Params    []RouteParam `json:"-"` // Query, header and path parameters.
+	Request   interface{}  `json:"-"` // Value of the type expected in the request body.
+	DataType  string       `json:"-"` // Key the response data is returned under.
+	Response  interface{}  `json:"-"` // Value of the type returned under DataType.
+	Paginated bool         `json:"-"` // Whether the response is a PaginatedResponse.
+	Cursored  bool         `json:"-"` // Whether the response is a PaginatedResponse with cursors.
+	Auth      bool         `json:"-"` // Whether the route requires a bearer token.
+}
+
+// RouteParam defines a parameter accepted by a route.
+type RouteParam struct {
+	Name        string // Name of the parameter.
+	In          string // Can be 'path', 'query' or 'header'.
+	Type        string // Can be 'string', 'integer', 'number' or 'boolean'.
+	Description string // What the parameter is for (optional).
+	Required    bool   // Whether the parameter must be provided.
+}
+
+// InfoOption - Supplies part of the information about this microservice from
+// code rather than from the environment.
+type InfoOption func(*MicroserviceInfo)
+
+// WithServiceName - Use the provided service name instead of SERVICE_NAME.
+func WithServiceName(name string) InfoOption {
+	return func(m *MicroserviceInfo) {
+		m.ServiceName = name
+	}
+}
+
+// WithServiceType - Use the provided service type instead of SERVICE_TYPE.
+func WithServiceType(serviceType string) InfoOption {
+	return func(m *MicroserviceInfo) {
+		m.ServiceType = serviceType
+	}
+}
+
+// WithServiceScope - Use the provided service scope instead of SERVICE_SCOPE.
+func WithServiceScope(scope string) InfoOption {
+	return func(m *MicroserviceInfo) {
+		m.ServiceScope = scope
+	}
+}
+
+// WithServiceVersion - Use the provided service version instead of SERVICE_VERSION.
+func WithServiceVersion(version string) InfoOption {
+	return func(m *MicroserviceInfo) {
+		m.ServiceVersion = version
+	}
+}
+
+// InfoEnvError - Error returned when the information about this microservice
+// cannot be loaded from the environment.
+type InfoEnvError struct {
+	Missing []string // Variables that have not been set.
+	Empty   []string // Variables that have been set to an empty string.
+	Others  []error  // Variables that cannot be read, such as unreadable files.
+}
+
+// Error - Error string listing every problem variable.
+func (e *InfoEnvError) Error() string {
+	var problems []string
+	if len(e.Missing) > 0 {
+		problems = append(problems, fmt.Sprintf("environment variables (%s) have not been set", strings.Join(e.Missing, ", ")))
+	}
+	if len(e.Empty) > 0 {
+		problems = append(problems, fmt.Sprintf("environment variables (%s) are empty", strings.Join(e.Empty, ", ")))
+	}
+	for _, err := range e.Others {
+		problems = append(problems, err.Error())
+	}
+	return "cannot load service info: " + strings.Join(problems, "; ")
+}
+
+// LoadMicroserviceInfo - Load the information about this microservice.
+// Any value not supplied by an option is read from its SERVICE_* environment
+// variable, and every variable that is missing, empty or cannot be read is
+// reported in a single *InfoEnvError. The variables are declared to env as
+// they are loaded.
+func LoadMicroserviceInfo(opts ...InfoOption) (*MicroserviceInfo, error) {
+	info := &MicroserviceInfo{}
+	for _, opt := range opts {
+		opt(info)
+	}
+
+	switch err := env.Load(info).(type) {
+	case nil:
+		return info, nil
+	case *env.LoadError:
+		envErr := &InfoEnvError{}
+		for _, err := range err.Errors {
+			missing, ok := err.(*env.MissingError)
+			switch {
+			case !ok:
+				envErr.Others = append(envErr.Others, err)
+			case missing.Empty:
+				envErr.Empty = append(envErr.Empty, missing.Name)
+			default:
+				envErr.Missing = append(envErr.Missing, missing.Name)
+			}
+		}
+		return nil, envErr
+	default:
+		return nil, err
+	}
 }
 
 // GetMicroserviceInfo - Get the information about this microservice.
+// If any of the information cannot be loaded, throw a fatal error.
 //
 // Return:
 //     *MicroserviceInfo - Object representing this microservice.
-func GetMicroserviceInfo() *MicroserviceInfo {
-	return &MicroserviceInfo{
-		ServiceName:    env.MustGet("SERVICE_NAME"),
-		ServiceType:    env.MustGet("SERVICE_TYPE"),
-		ServiceScope:   env.MustGet("SERVICE_SCOPE"),
-		ServiceVersion: env.MustGet("SERVICE_VERSION"),
+func GetMicroserviceInfo(opts ...InfoOption) *MicroserviceInfo {
+	info, err := LoadMicroserviceInfo(opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return info
+}
+
+// InfoRoute - Get the route serving the information about this microservice,
+// including its build information and uptime. When router is not nil, the
+// endpoints listed are read from it on every request, so routes registered
+// after this one are included too.
+func InfoRoute(info *MicroserviceInfo, router *Router) Route {
+	return Route{
+		Path:   InfoPath,
+		Method: http.MethodGet,
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			document := *info
+			document.Build = GetBuildInfo()
+			if router != nil {
+				document.Endpoints = router.Routes()
+			}
+			response.New(http.StatusOK, "", &response.Data{
+				Type:    "info",
+				Content: document,
+			}).WriteTo(w)
+		},
 	}
 }
@@ -1,9 +1,17 @@
 package microservicecore
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/LUSHDigital/microservice-core-golang/response"
 )
 
 // Example environment variables for testing.
@@ -71,3 +79,146 @@ func ExampleGetMicroserviceInfo() {
 	// testing
 	// 0.0.1
 }
+
+// TestInfoRoute - Test the info route serves the registered endpoints.
+func TestInfoRoute(t *testing.T) {
+	info := &MicroserviceInfo{
+		ServiceName:    "example-service",
+		ServiceType:    "examples",
+		ServiceScope:   "testing",
+		ServiceVersion: "0.0.1",
+	}
+	router, err := NewRouter(info, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Handle(InfoRoute(info, router)); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Handle(echoRoute(http.MethodGet, "/products/{id}")); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, InfoPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d got %d", http.StatusOK, rec.Code)
+	}
+
+	var resp response.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var served MicroserviceInfo
+	if err := resp.ExtractData("info", &served); err != nil {
+		t.Fatal(err)
+	}
+
+	if served.ServiceName != info.ServiceName {
+		t.Errorf("Expected %v, got %v", info.ServiceName, served.ServiceName)
+	}
+	if served.ServiceVersion != info.ServiceVersion {
+		t.Errorf("Expected %v, got %v", info.ServiceVersion, served.ServiceVersion)
+	}
+	if served.Build == nil || served.Build.GoVersion != runtime.Version() {
+		t.Errorf("Expected build info for %s, got %+v", runtime.Version(), served.Build)
+	}
+	if len(served.Endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(served.Endpoints))
+	}
+	if served.Endpoints[1].Path != "/products/{id}" || served.Endpoints[1].Method != http.MethodGet {
+		t.Errorf("Expected GET /products/{id}, got %s %s", served.Endpoints[1].Method, served.Endpoints[1].Path)
+	}
+}
+
+// TestLoadMicroserviceInfo - Test every problem variable is reported at once.
+func TestLoadMicroserviceInfo(t *testing.T) {
+	tt := []struct {
+		name            string
+		env             map[string]string
+		opts            []InfoOption
+		expectedMissing []string
+		expectedEmpty   []string
+		expectedOthers  int
+		expectedName    string
+	}{
+		{
+			name:         "all variables set",
+			env:          exampleEnvVars,
+			expectedName: "example-service",
+		},
+		{
+			name: "missing and empty variables",
+			env: map[string]string{
+				"SERVICE_NAME":  "example-service",
+				"SERVICE_SCOPE": "",
+			},
+			expectedMissing: []string{"SERVICE_TYPE", "SERVICE_VERSION"},
+			expectedEmpty:   []string{"SERVICE_SCOPE"},
+		},
+		{
+			name: "variable set both directly and as a file",
+			env: map[string]string{
+				"SERVICE_NAME":      "example-service",
+				"SERVICE_NAME_FILE": "/run/secrets/service-name",
+				"SERVICE_TYPE":      "examples",
+				"SERVICE_SCOPE":     "testing",
+				"SERVICE_VERSION":   "0.0.1",
+			},
+			expectedOthers: 1,
+		},
+		{
+			name: "values supplied from code",
+			env:  map[string]string{},
+			opts: []InfoOption{
+				WithServiceName("coded-service"),
+				WithServiceType("examples"),
+				WithServiceScope("testing"),
+				WithServiceVersion("0.0.2"),
+			},
+			expectedName: "coded-service",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for key := range exampleEnvVars {
+				os.Unsetenv(key)
+			}
+			for key, value := range tc.env {
+				os.Setenv(key, value)
+				if _, ok := exampleEnvVars[key]; !ok {
+					defer os.Unsetenv(key)
+				}
+			}
+
+			info, err := LoadMicroserviceInfo(tc.opts...)
+			if tc.expectedMissing == nil && tc.expectedEmpty == nil && tc.expectedOthers == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.ServiceName != tc.expectedName {
+					t.Errorf("Expected %v, got %v", tc.expectedName, info.ServiceName)
+				}
+				return
+			}
+
+			envErr, ok := err.(*InfoEnvError)
+			if !ok {
+				t.Fatalf("Expected *InfoEnvError, got (%T) %v", err, err)
+			}
+			if !reflect.DeepEqual(envErr.Missing, tc.expectedMissing) {
+				t.Errorf("Expected missing %v, got %v", tc.expectedMissing, envErr.Missing)
+			}
+			if !reflect.DeepEqual(envErr.Empty, tc.expectedEmpty) {
+				t.Errorf("Expected empty %v, got %v", tc.expectedEmpty, envErr.Empty)
+			}
+			if len(envErr.Others) != tc.expectedOthers {
+				t.Errorf("Expected %d other errors, got %v", tc.expectedOthers, envErr.Others)
+			}
+			if tc.expectedOthers > 0 && !strings.Contains(err.Error(), "SERVICE_NAME") {
+				t.Errorf("Expected the error to name the variable, got %v", err)
+			}
+		})
+	}
+}
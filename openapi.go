@@ -0,0 +1,404 @@
+package microservicecore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/LUSHDigital/microservice-core-golang/pagination"
+	"github.com/LUSHDigital/microservice-core-golang/response"
+	"github.com/LUSHDigital/microservice-core-golang/transport/config"
+)
+
+const (
+	// OpenAPIPath - Path the OpenAPI document route is served on.
+	OpenAPIPath = "/openapi.json"
+
+	// OpenAPIVersion - Version of the OpenAPI specification documents conform to.
+	OpenAPIVersion = "3.0.3"
+
+	// openAPIBearerAuth - Name of the security scheme used by routes requiring auth.
+	openAPIBearerAuth = "bearerAuth"
+)
+
+// OpenAPISchema - A JSON schema object as used by OpenAPI.
+type OpenAPISchema map[string]interface{}
+
+// OpenAPIDocument - An OpenAPI 3 document describing a set of routes.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+// OpenAPIInfo - Metadata about the documented API.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIOperation - A single method on a path.
+type OpenAPIOperation struct {
+	Summary     string                      `json:"summary,omitempty"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+}
+
+// OpenAPIParameter - A parameter accepted by an operation.
+type OpenAPIParameter struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required"`
+	Schema      OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIRequestBody - The body accepted by an operation.
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse - A response returned by an operation.
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType - The schema of a request or response body.
+type OpenAPIMediaType struct {
+	Schema OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIComponents - Reusable schemas referenced from the rest of the document.
+type OpenAPIComponents struct {
+	Schemas         map[string]OpenAPISchema `json:"schemas"`
+	SecuritySchemes map[string]OpenAPISchema `json:"securitySchemes,omitempty"`
+}
+
+// NewOpenAPIDocument - Generate an OpenAPI document describing the provided
+// routes, wrapping every documented data type in the standard response envelope.
+func NewOpenAPIDocument(info *MicroserviceInfo, routes []Route) *OpenAPIDocument {
+	g := newOpenAPIGenerator()
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info: OpenAPIInfo{
+			Title:       info.ServiceName,
+			Description: strings.TrimSpace(fmt.Sprintf("%s %s", info.ServiceScope, info.ServiceType)),
+			Version:     info.ServiceVersion,
+		},
+		Paths: map[string]map[string]*OpenAPIOperation{},
+	}
+
+	// Only one operation can describe a method on a path, so when several
+	// versions of a route exist the latest one is documented.
+	latest := map[string]int{}
+	for _, route := range routes {
+		operations, ok := doc.Paths[route.Path]
+		if !ok {
+			operations = map[string]*OpenAPIOperation{}
+			doc.Paths[route.Path] = operations
+		}
+
+		method := strings.ToLower(route.Method)
+		version := 0
+		for _, v := range route.Versions {
+			if v > version {
+				version = v
+			}
+		}
+		if v, ok := latest[route.Path+" "+method]; ok && v > version {
+			continue
+		}
+		latest[route.Path+" "+method] = version
+		operations[method] = g.operation(route)
+	}
+
+	doc.Components = OpenAPIComponents{Schemas: g.schemas}
+	if g.auth {
+		doc.Components.SecuritySchemes = map[string]OpenAPISchema{
+			openAPIBearerAuth: {
+				"type":         "http",
+				"scheme":       "bearer",
+				"bearerFormat": "JWT",
+			},
+		}
+	}
+	return doc
+}
+
+// OpenAPIRoute - Get the route serving the OpenAPI document for every route
+// registered with the router.
+func OpenAPIRoute(info *MicroserviceInfo, router *Router) Route {
+	return Route{
+		Path:    OpenAPIPath,
+		Method:  http.MethodGet,
+		Summary: "OpenAPI document describing this service",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			j, err := json.Marshal(NewOpenAPIDocument(info, router.Routes()))
+			if err != nil {
+				response.InternalError(err).WriteTo(w)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(j)
+		},
+	}
+}
+
+// openAPIGenerator - Collects component schemas while operations are generated.
+type openAPIGenerator struct {
+	schemas map[string]OpenAPISchema
+	types   map[reflect.Type]string
+	auth    bool
+}
+
+// newOpenAPIGenerator - Prepare a generator with the response envelope schemas registered.
+func newOpenAPIGenerator() *openAPIGenerator {
+	g := &openAPIGenerator{
+		schemas: map[string]OpenAPISchema{},
+		types:   map[reflect.Type]string{},
+	}
+	g.schemas["Response"] = OpenAPISchema{
+		"type":     "object",
+		"required": []string{"status", "code", "message"},
+		"properties": map[string]OpenAPISchema{
+			"status":  {"type": "string", "enum": []string{response.StatusOk, response.StatusFail}},
+			"code":    {"type": "integer"},
+			"message": {"type": "string"},
+		},
+	}
+	return g
+}
+
+// operation - Generate the operation for a route.
+func (g *openAPIGenerator) operation(route Route) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		Summary:    route.Summary,
+		Parameters: g.parameters(route),
+		Responses: map[string]*OpenAPIResponse{
+			"200": {
+				Description: "Success",
+				Content:     jsonContent(g.envelope(route)),
+			},
+			"default": {
+				Description: "Error",
+				Content:     jsonContent(ref("Response")),
+			},
+		},
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content:  jsonContent(g.schema(reflect.TypeOf(route.Request))),
+		}
+	}
+
+	if route.Auth {
+		g.auth = true
+		op.Security = []map[string][]string{{openAPIBearerAuth: {}}}
+	}
+	return op
+}
+
+// parameters - Generate the parameters for a route, adding any path parameter
+// from its template that has not been declared explicitly.
+func (g *openAPIGenerator) parameters(route Route) []OpenAPIParameter {
+	var params []OpenAPIParameter
+	declared := map[string]bool{}
+	for _, param := range route.Params {
+		paramType := param.Type
+		if paramType == "" {
+			paramType = "string"
+		}
+		params = append(params, OpenAPIParameter{
+			Name:        param.Name,
+			In:          param.In,
+			Description: param.Description,
+			Required:    param.Required || param.In == "path",
+			Schema:      OpenAPISchema{"type": paramType},
+		})
+		if param.In == "path" {
+			declared[param.Name] = true
+		}
+	}
+
+	if len(route.Versions) > 0 {
+		params = append(params, OpenAPIParameter{
+			Name:        config.ServiceVersionHeader,
+			In:          "header",
+			Description: "Major API version to use",
+			Schema:      OpenAPISchema{"type": "integer", "enum": route.Versions},
+		})
+	}
+
+	p, err := parsePattern(route.Path)
+	if err != nil {
+		return params
+	}
+	for _, seg := range p.segments {
+		if !seg.param || declared[seg.value] {
+			continue
+		}
+		params = append(params, OpenAPIParameter{
+			Name:     seg.value,
+			In:       "path",
+			Required: true,
+			Schema:   OpenAPISchema{"type": "string"},
+		})
+	}
+	return params
+}
+
+// envelope - Generate the schema of the successful response for a route.
+func (g *openAPIGenerator) envelope(route Route) OpenAPISchema {
+	properties := map[string]OpenAPISchema{}
+	if route.Response != nil && route.DataType != "" {
+		dataType := strings.Replace(strings.ToLower(route.DataType), " ", "-", -1)
+		properties["data"] = OpenAPISchema{
+			"type": "object",
+			"properties": map[string]OpenAPISchema{
+				dataType: g.schema(reflect.TypeOf(route.Response)),
+			},
+		}
+	}
+	if route.Paginated {
+		properties["pagination"] = g.schema(reflect.TypeOf(pagination.Response{}))
+	}
+	if route.Cursored {
+		properties["cursor"] = g.schema(reflect.TypeOf(pagination.CursorResponse{}))
+	}
+
+	if len(properties) == 0 {
+		return ref("Response")
+	}
+	return OpenAPISchema{
+		"allOf": []OpenAPISchema{
+			ref("Response"),
+			{"type": "object", "properties": properties},
+		},
+	}
+}
+
+// schema - Generate the schema for a Go type, registering named structs as components.
+func (g *openAPIGenerator) schema(t reflect.Type) OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return OpenAPISchema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return OpenAPISchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return OpenAPISchema{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return OpenAPISchema{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return OpenAPISchema{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return OpenAPISchema{"type": "number", "format": "double"}
+	case reflect.String:
+		return OpenAPISchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return OpenAPISchema{"type": "string", "format": "byte"}
+		}
+		return OpenAPISchema{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return OpenAPISchema{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name, ok := g.types[t]
+		if !ok {
+			name = g.componentName(t)
+			g.types[t] = name
+			// Reserve the name before generating the schema, so recursive
+			// types reference it rather than looping forever.
+			g.schemas[name] = OpenAPISchema{}
+			g.schemas[name] = g.structSchema(t)
+		}
+		return ref(name)
+	default:
+		return OpenAPISchema{}
+	}
+}
+
+// structSchema - Generate an object schema from the JSON encoding of a struct.
+func (g *openAPIGenerator) structSchema(t reflect.Type) OpenAPISchema {
+	properties := map[string]OpenAPISchema{}
+	g.addProperties(properties, t)
+	return OpenAPISchema{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// addProperties - Add a property for every field encoding/json would encode.
+func (g *openAPIGenerator) addProperties(properties map[string]OpenAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		// Untagged embedded structs have their fields promoted.
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addProperties(properties, embedded)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schema(field.Type)
+	}
+}
+
+// componentName - Pick a unique component name for a named type.
+func (g *openAPIGenerator) componentName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := g.schemas[name]; !taken {
+		return name
+	}
+	pkg := path.Base(t.PkgPath())
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + name
+}
+
+// ref - Get a schema referencing a component.
+func ref(name string) OpenAPISchema {
+	return OpenAPISchema{"$ref": "#/components/schemas/" + name}
+}
+
+// jsonContent - Get the content map for a JSON body with the provided schema.
+func jsonContent(schema OpenAPISchema) map[string]OpenAPIMediaType {
+	return map[string]OpenAPIMediaType{
+		"application/json": {Schema: schema},
+	}
+}
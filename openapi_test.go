@@ -0,0 +1,157 @@
+package microservicecore
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Example types for documenting routes.
+type (
+	exampleProduct struct {
+		ID        int64           `json:"id"`
+		Name      string          `json:"name"`
+		Tags      []string        `json:"tags,omitempty"`
+		CreatedAt time.Time       `json:"created_at"`
+		Related   *exampleProduct `json:"related,omitempty"`
+		internal  string
+	}
+
+	exampleProductInput struct {
+		Name string `json:"name"`
+	}
+)
+
+func TestNewOpenAPIDocument(t *testing.T) {
+	info := &MicroserviceInfo{
+		ServiceName:    "example-service",
+		ServiceType:    "examples",
+		ServiceScope:   "testing",
+		ServiceVersion: "0.0.1",
+	}
+	routes := []Route{
+		{
+			Path:      "/products",
+			Method:    http.MethodGet,
+			Summary:   "List products",
+			Params:    []RouteParam{{Name: "page", In: "query", Type: "integer"}},
+			DataType:  "products",
+			Response:  []exampleProduct{},
+			Paginated: true,
+		},
+		{
+			Path:     "/products/{id}",
+			Method:   http.MethodPut,
+			Request:  exampleProductInput{},
+			DataType: "product",
+			Response: exampleProduct{},
+			Auth:     true,
+		},
+		{
+			Path:     "/products/{id}/reviews",
+			Method:   http.MethodGet,
+			DataType: "reviews",
+			Response: []string{},
+			Cursored: true,
+		},
+	}
+
+	doc := NewOpenAPIDocument(info, routes)
+
+	if doc.OpenAPI != OpenAPIVersion {
+		t.Errorf("expected version %s got %s", OpenAPIVersion, doc.OpenAPI)
+	}
+	if doc.Info.Title != info.ServiceName || doc.Info.Version != info.ServiceVersion {
+		t.Errorf("unexpected info %+v", doc.Info)
+	}
+
+	list := doc.Paths["/products"]["get"]
+	if list == nil {
+		t.Fatal("expected an operation for GET /products")
+	}
+	if list.Summary != "List products" {
+		t.Errorf("expected summary %q got %q", "List products", list.Summary)
+	}
+	envelope := list.Responses["200"].Content["application/json"].Schema["allOf"].([]OpenAPISchema)
+	properties := envelope[1]["properties"].(map[string]OpenAPISchema)
+	if _, ok := properties["pagination"]; !ok {
+		t.Error("expected the paginated envelope to include pagination")
+	}
+	data := properties["data"]["properties"].(map[string]OpenAPISchema)
+	expectedItems := OpenAPISchema{"type": "array", "items": ref("exampleProduct")}
+	if !reflect.DeepEqual(data["products"], expectedItems) {
+		t.Errorf("expected %v got %v", expectedItems, data["products"])
+	}
+
+	update := doc.Paths["/products/{id}"]["put"]
+	if update == nil {
+		t.Fatal("expected an operation for PUT /products/{id}")
+	}
+	if len(update.Parameters) != 1 || update.Parameters[0].Name != "id" || !update.Parameters[0].Required {
+		t.Errorf("expected a required id path parameter got %+v", update.Parameters)
+	}
+	if update.RequestBody == nil {
+		t.Error("expected a request body")
+	}
+	if len(update.Security) != 1 {
+		t.Error("expected the operation to require auth")
+	}
+	if _, ok := doc.Components.SecuritySchemes[openAPIBearerAuth]; !ok {
+		t.Error("expected the bearer security scheme to be defined")
+	}
+
+	product := doc.Components.Schemas["exampleProduct"]["properties"].(map[string]OpenAPISchema)
+	expectedFields := []string{"id", "name", "tags", "created_at", "related"}
+	if len(product) != len(expectedFields) {
+		t.Errorf("expected %d properties got %v", len(expectedFields), product)
+	}
+	for _, field := range expectedFields {
+		if _, ok := product[field]; !ok {
+			t.Errorf("expected property %s", field)
+		}
+	}
+	if !reflect.DeepEqual(product["related"], ref("exampleProduct")) {
+		t.Errorf("expected a recursive reference got %v", product["related"])
+	}
+	if _, ok := doc.Components.Schemas["PaginationResponse"]; !ok {
+		t.Error("expected the pagination schema not to clash with the response envelope")
+	}
+
+	reviews := doc.Paths["/products/{id}/reviews"]["get"]
+	envelope = reviews.Responses["200"].Content["application/json"].Schema["allOf"].([]OpenAPISchema)
+	properties = envelope[1]["properties"].(map[string]OpenAPISchema)
+	if _, ok := properties["cursor"]; !ok {
+		t.Error("expected the cursor paginated envelope to include the cursors")
+	}
+	if _, ok := properties["pagination"]; ok {
+		t.Error("expected the cursor paginated envelope not to include page numbers")
+	}
+}
+
+func TestOpenAPIRoute(t *testing.T) {
+	info := &MicroserviceInfo{ServiceName: "example-service", ServiceVersion: "0.0.1"}
+	router, err := NewRouter(info, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Handle(OpenAPIRoute(info, router)); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, OpenAPIPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected code %d got %d", http.StatusOK, rec.Code)
+	}
+
+	var doc OpenAPIDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Paths[OpenAPIPath]["get"]; !ok {
+		t.Errorf("expected the document to describe itself got %v", doc.Paths)
+	}
+}
@@ -0,0 +1,170 @@
+package pagination
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Cursor represents the position of a row in a sorted data set.
+type Cursor struct {
+	// Values of the sort key of the row, in sort order. Numbers are decoded
+	// as json.Number, so large identifiers survive the round trip.
+	Key []interface{} `json:"k"`
+
+	// Whether the page holds the rows before the key, rather than after it.
+	Before bool `json:"b,omitempty"`
+}
+
+// EncodeCursor returns an opaque cursor signed with the secret, so a cursor
+// altered by the API consumer is rejected when decoded. The secret must not
+// be empty.
+func EncodeCursor(secret []byte, cursor *Cursor) (string, error) {
+	if len(secret) == 0 {
+		return "", ErrInvalidSecret
+	}
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(append(payload, sign(secret, payload)...)), nil
+}
+
+// DecodeCursor returns the cursor encoded by EncodeCursor with the same
+// secret, or ErrInvalidCursor when it was altered or is malformed.
+func DecodeCursor(secret []byte, encoded string) (*Cursor, error) {
+	if len(secret) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(raw) <= sha256.Size {
+		return nil, ErrInvalidCursor
+	}
+	payload, signature := raw[:len(raw)-sha256.Size], raw[len(raw)-sha256.Size:]
+	if !hmac.Equal(signature, sign(secret, payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	cursor := &Cursor{}
+	if err := decoder.Decode(cursor); err != nil || len(cursor.Key) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// sign returns the HMAC-SHA256 of the payload.
+func sign(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// CursorPaginator manages keyset pagination of a data set: rather than
+// skipping an offset, each page continues from the sort key of the last row
+// of the previous one, which stays fast on large tables and does not shift
+// when rows are inserted.
+//
+// Fetch GetLimit rows past the cursor, in reverse sort order when the cursor
+// points before its key, then report the page with SetResults:
+//
+//	cursor := paginator.GetCursor() // nil on the first page.
+//	rows := fetch(cursor, paginator.GetLimit())
+//	more := len(rows) > paginator.GetPerPage()
+//	if more {
+//	    rows = rows[:paginator.GetPerPage()]
+//	}
+//	paginator.SetResults(keyOf(rows[0]), keyOf(rows[len(rows)-1]), more)
+type CursorPaginator struct {
+	secret  []byte  // The secret cursors are signed with.
+	perPage int     // The number of items per page.
+	cursor  *Cursor // Where the page starts, nil for the first page.
+	next    *string // The cursor of the next page (if possible).
+	prev    *string // The cursor of the previous page (if possible).
+}
+
+// NewCursorPaginator returns a new CursorPaginator for pages of perPage
+// items, starting at the encoded cursor or at the first page when it is
+// empty. Cursors are signed with the secret, which must be kept private and
+// must not be empty.
+func NewCursorPaginator(secret []byte, perPage int, cursor string) (*CursorPaginator, error) {
+	if len(secret) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	if perPage <= 0 {
+		return nil, ErrInvalidPerPage
+	}
+	paginator := &CursorPaginator{
+		secret:  secret,
+		perPage: perPage,
+	}
+
+	if cursor != "" {
+		decoded, err := DecodeCursor(secret, cursor)
+		if err != nil {
+			return nil, err
+		}
+		paginator.cursor = decoded
+	}
+	return paginator, nil
+}
+
+// GetPerPage returns the number of items per page.
+func (p *CursorPaginator) GetPerPage() int {
+	return p.perPage
+}
+
+// GetLimit returns the number of rows to fetch: one more than fits on the
+// page, telling whether another page follows.
+func (p *CursorPaginator) GetLimit() int {
+	return p.perPage + 1
+}
+
+// GetCursor returns where the page starts, or nil for the first page.
+func (p *CursorPaginator) GetCursor() *Cursor {
+	return p.cursor
+}
+
+// SetResults records the sort keys of the first and last rows of the page,
+// in sort order, and whether more rows exist beyond the page in the direction
+// it was fetched in. Leave the keys nil when the page is empty. Returns an
+// error when the keys cannot be encoded into cursors.
+func (p *CursorPaginator) SetResults(first, last []interface{}, hasMore bool) error {
+	p.next, p.prev = nil, nil
+	if first == nil || last == nil {
+		return nil
+	}
+
+	hasNext, hasPrev := hasMore, p.cursor != nil
+	if p.cursor != nil && p.cursor.Before {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	if hasNext {
+		next, err := EncodeCursor(p.secret, &Cursor{Key: last})
+		if err != nil {
+			return err
+		}
+		p.next = &next
+	}
+	if hasPrev {
+		prev, err := EncodeCursor(p.secret, &Cursor{Key: first, Before: true})
+		if err != nil {
+			return err
+		}
+		p.prev = &prev
+	}
+	return nil
+}
+
+// PrepareResponse returns a prepared cursor pagination response.
+func (p *CursorPaginator) PrepareResponse() *CursorResponse {
+	return &CursorResponse{
+		PerPage:    p.perPage,
+		NextCursor: p.next,
+		PrevCursor: p.prev,
+	}
+}
@@ -8,4 +8,16 @@ var (
 
 	// ErrCalculateLastPage is used when the pagination last page could not be calculated.
 	ErrCalculateLastPage = errors.New("cannot calculate last page: insufficient data")
+
+	// ErrInvalidPerPage is used when the number of items per page is not positive.
+	ErrInvalidPerPage = errors.New("invalid number of items per page")
+
+	// ErrInvalidCursor is used when a cursor is malformed or has been tampered with.
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrInvalidSecret is used when the secret cursors are signed with is empty.
+	ErrInvalidSecret = errors.New("invalid cursor secret: must not be empty")
+
+	// ErrNotSlice is used when a collection to paginate is not a slice or an array.
+	ErrNotSlice = errors.New("cannot paginate: expected a slice or an array")
 )
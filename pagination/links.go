@@ -0,0 +1,85 @@
+package pagination
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Links holds the URLs of the pages around the current one.
+type Links struct {
+	Self  string `json:"self,omitempty"`  // The URL of the current page.
+	First string `json:"first,omitempty"` // The URL of the first page.
+	Last  string `json:"last,omitempty"`  // The URL of the last page (if any).
+	Next  string `json:"next,omitempty"`  // The URL of the next page (if possible).
+	Prev  string `json:"prev,omitempty"`  // The URL of the previous page (if possible).
+}
+
+// NewLinks returns the links to the pages around a pagination response. They
+// are built from the URL of the request by replacing its page and per page
+// query parameters, named as in the options, keeping every other parameter.
+func NewLinks(u *url.URL, r *Response, opts Options) *Links {
+	opts = opts.withDefaults()
+	page := func(n int) string {
+		query := u.Query()
+		query.Set(opts.PageParam, strconv.Itoa(n))
+		query.Set(opts.PerPageParam, strconv.Itoa(r.PerPage))
+		link := *u
+		link.RawQuery = query.Encode()
+		return link.String()
+	}
+
+	links := &Links{Self: page(r.CurrentPage), First: page(1)}
+	if r.LastPage > 0 {
+		links.Last = page(r.LastPage)
+	}
+	if r.NextPage != nil {
+		links.Next = page(*r.NextPage)
+	}
+	if r.PrevPage != nil {
+		links.Prev = page(*r.PrevPage)
+	}
+	return links
+}
+
+// Header returns the links to the other pages formatted as the value of an
+// RFC 5988 Link header.
+func (l *Links) Header() string {
+	var values []string
+	for _, link := range []struct{ rel, url string }{
+		{"next", l.Next},
+		{"prev", l.Prev},
+		{"first", l.First},
+		{"last", l.Last},
+	} {
+		if link.url != "" {
+			values = append(values, "<"+link.url+`>; rel="`+link.rel+`"`)
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+// RequestURL returns the absolute URL a request was made to, as seen by the
+// API consumer. The X-Forwarded-Proto and X-Forwarded-Host headers take
+// precedence over the connection only when the options trust them, as any
+// client can set them.
+func RequestURL(r *http.Request, opts Options) *url.URL {
+	u := *r.URL
+	u.Scheme = "http"
+	if r.TLS != nil {
+		u.Scheme = "https"
+	}
+	u.Host = r.Host
+	if !opts.TrustForwardedHeaders {
+		return &u
+	}
+
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		u.Scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	if host := r.Header.Get("X-Forwarded-Host"); host != "" {
+		u.Host = strings.TrimSpace(strings.Split(host, ",")[0])
+	}
+	return &u
+}
@@ -1,7 +1,15 @@
 package pagination
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -380,3 +388,560 @@ func TestPaginator_PrepareResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeCursor(t *testing.T) {
+	secret := []byte("secret")
+	encoded, err := EncodeCursor(secret, &Cursor{Key: []interface{}{"2018-06-01", 9007199254740993}, Before: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cursor, err := DecodeCursor(secret, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Cursor{Key: []interface{}{"2018-06-01", json.Number("9007199254740993")}, Before: true}
+	if !reflect.DeepEqual(cursor, expected) {
+		t.Errorf("expected %v got %v", expected, cursor)
+	}
+
+	tampered := []byte(encoded)
+	tampered[2] ^= 1
+	for name, encoded := range map[string]string{
+		"tampered":     string(tampered),
+		"other secret": func() string { s, _ := EncodeCursor([]byte("other"), &Cursor{Key: []interface{}{1}}); return s }(),
+		"not base64":   "not a cursor!",
+		"too short":    "YWJj",
+	} {
+		if _, err := DecodeCursor(secret, encoded); err != ErrInvalidCursor {
+			t.Errorf("%s: expected ErrInvalidCursor got %v", name, err)
+		}
+	}
+
+	if _, err := EncodeCursor(nil, &Cursor{Key: []interface{}{1}}); err != ErrInvalidSecret {
+		t.Errorf("expected ErrInvalidSecret encoding got %v", err)
+	}
+	if _, err := DecodeCursor([]byte{}, encoded); err != ErrInvalidSecret {
+		t.Errorf("expected ErrInvalidSecret decoding got %v", err)
+	}
+}
+
+func TestCursorPaginator(t *testing.T) {
+	secret := []byte("secret")
+	ids := []int{1, 2, 3, 4, 5, 6, 7}
+
+	// fetch returns a page of ids the way a keyset query would.
+	fetch := func(cursor string) ([]int, *CursorResponse) {
+		paginator, err := NewCursorPaginator(secret, 3, cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var rows []int
+		c := paginator.GetCursor()
+		if c != nil && c.Before {
+			key, _ := c.Key[0].(json.Number).Int64()
+			for i := len(ids) - 1; i >= 0 && len(rows) < paginator.GetLimit(); i-- {
+				if ids[i] < int(key) {
+					rows = append([]int{ids[i]}, rows...)
+				}
+			}
+		} else {
+			for _, id := range ids {
+				if c != nil {
+					if key, _ := c.Key[0].(json.Number).Int64(); id <= int(key) {
+						continue
+					}
+				}
+				if len(rows) < paginator.GetLimit() {
+					rows = append(rows, id)
+				}
+			}
+		}
+
+		more := len(rows) > paginator.GetPerPage()
+		if more && c != nil && c.Before {
+			rows = rows[1:]
+		} else if more {
+			rows = rows[:paginator.GetPerPage()]
+		}
+		if len(rows) > 0 {
+			err = paginator.SetResults([]interface{}{rows[0]}, []interface{}{rows[len(rows)-1]}, more)
+		} else {
+			err = paginator.SetResults(nil, nil, more)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rows, paginator.PrepareResponse()
+	}
+
+	rows, first := fetch("")
+	if !reflect.DeepEqual(rows, []int{1, 2, 3}) || first.NextCursor == nil || first.PrevCursor != nil {
+		t.Fatalf("unexpected first page %v %+v", rows, first)
+	}
+	rows, second := fetch(*first.NextCursor)
+	if !reflect.DeepEqual(rows, []int{4, 5, 6}) || second.NextCursor == nil || second.PrevCursor == nil {
+		t.Fatalf("unexpected second page %v %+v", rows, second)
+	}
+	rows, last := fetch(*second.NextCursor)
+	if !reflect.DeepEqual(rows, []int{7}) || last.NextCursor != nil || last.PrevCursor == nil {
+		t.Fatalf("unexpected last page %v %+v", rows, last)
+	}
+	rows, back := fetch(*last.PrevCursor)
+	if !reflect.DeepEqual(rows, []int{4, 5, 6}) || back.NextCursor == nil || back.PrevCursor == nil {
+		t.Fatalf("unexpected page going back %v %+v", rows, back)
+	}
+	rows, start := fetch(*back.PrevCursor)
+	if !reflect.DeepEqual(rows, []int{1, 2, 3}) || start.NextCursor == nil || start.PrevCursor != nil {
+		t.Fatalf("unexpected page back at the start %v %+v", rows, start)
+	}
+
+	if _, err := NewCursorPaginator(secret, 0, ""); err != ErrInvalidPerPage {
+		t.Errorf("expected ErrInvalidPerPage got %v", err)
+	}
+	if _, err := NewCursorPaginator(secret, 3, "bogus"); err != ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor got %v", err)
+	}
+	if _, err := NewCursorPaginator(nil, 3, ""); err != ErrInvalidSecret {
+		t.Errorf("expected ErrInvalidSecret got %v", err)
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	tt := []struct {
+		name            string
+		query           string
+		opts            Options
+		expectedPage    int
+		expectedPerPage int
+		expectedParam   string
+	}{
+		{
+			name:            "defaults",
+			expectedPage:    1,
+			expectedPerPage: DefaultPerPage,
+		},
+		{
+			name:            "requested page",
+			query:           "page=3&per_page=25",
+			expectedPage:    3,
+			expectedPerPage: 25,
+		},
+		{
+			name:            "clamped per page",
+			query:           "per_page=500",
+			expectedPage:    1,
+			expectedPerPage: DefaultMaxPerPage,
+		},
+		{
+			name:            "custom options",
+			query:           "p=2&limit=80&page=x",
+			opts:            Options{PageParam: "p", PerPageParam: "limit", DefaultPerPage: 20, MaxPerPage: 50},
+			expectedPage:    2,
+			expectedPerPage: 50,
+		},
+		{
+			name:            "custom default",
+			opts:            Options{DefaultPerPage: 20},
+			expectedPage:    1,
+			expectedPerPage: 20,
+		},
+		{
+			name:          "page overflowing the offset",
+			query:         "page=9223372036854775807&per_page=10",
+			expectedParam: "page",
+		},
+		{
+			name:          "negative page",
+			query:         "page=-1",
+			expectedParam: "page",
+		},
+		{
+			name:          "zero page",
+			query:         "page=0",
+			expectedParam: "page",
+		},
+		{
+			name:          "non-numeric per page",
+			query:         "per_page=ten",
+			expectedParam: "per_page",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/products?"+tc.query, nil)
+			paginator, err := FromRequest(r, tc.opts)
+			if tc.expectedParam != "" {
+				paramErr, ok := err.(*ParamError)
+				if !ok || paramErr.Name != tc.expectedParam {
+					t.Fatalf("expected a *ParamError for %s got (%T) %v", tc.expectedParam, err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if paginator.GetPage() != tc.expectedPage || paginator.GetPerPage() != tc.expectedPerPage {
+				t.Errorf("expected page %d of %d got page %d of %d", tc.expectedPage, tc.expectedPerPage, paginator.GetPage(), paginator.GetPerPage())
+			}
+		})
+	}
+}
+
+func TestNewLinks(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/products?colour=red&page=2&per_page=10&tag=a&tag=b", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+	r.Header.Set("X-Forwarded-Host", "attacker.example.org")
+	if u := RequestURL(r, Options{}); u.String() != "http://example.com/products?colour=red&page=2&per_page=10&tag=a&tag=b" {
+		t.Errorf("expected forwarded headers to be ignored got %s", u)
+	}
+	r.Header.Del("X-Forwarded-Host")
+	u := RequestURL(r, Options{TrustForwardedHeaders: true})
+	if u.String() != "https://example.com/products?colour=red&page=2&per_page=10&tag=a&tag=b" {
+		t.Fatalf("unexpected request URL %s", u)
+	}
+
+	paginator, err := NewPaginator(10, 2, 35)
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := NewLinks(u, paginator.PrepareResponse(), Options{})
+	expected := &Links{
+		Self:  "https://example.com/products?colour=red&page=2&per_page=10&tag=a&tag=b",
+		First: "https://example.com/products?colour=red&page=1&per_page=10&tag=a&tag=b",
+		Last:  "https://example.com/products?colour=red&page=4&per_page=10&tag=a&tag=b",
+		Next:  "https://example.com/products?colour=red&page=3&per_page=10&tag=a&tag=b",
+		Prev:  "https://example.com/products?colour=red&page=1&per_page=10&tag=a&tag=b",
+	}
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("expected %+v got %+v", expected, links)
+	}
+
+	expectedHeader := `<` + expected.Next + `>; rel="next", <` + expected.Prev + `>; rel="prev", <` +
+		expected.First + `>; rel="first", <` + expected.Last + `>; rel="last"`
+	if header := links.Header(); header != expectedHeader {
+		t.Errorf("expected header %s got %s", expectedHeader, header)
+	}
+
+	empty, err := NewPaginator(10, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	links = NewLinks(u, empty.PrepareResponse(), Options{PageParam: "p"})
+	if links.Next != "" || links.Prev != "" || links.Last != "" {
+		t.Errorf("expected only a first link for an empty data set got %+v", links)
+	}
+	if links.First != "https://example.com/products?colour=red&p=1&page=2&per_page=10&tag=a&tag=b" {
+		t.Errorf("expected the custom page parameter to be set got %s", links.First)
+	}
+}
+
+func TestResponse_SetLinks(t *testing.T) {
+	paginator, err := NewPaginator(10, 1, 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := paginator.PrepareResponse()
+
+	j, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(j), "links") {
+		t.Errorf("expected no links unless set got %s", j)
+	}
+
+	resp.SetLinks(httptest.NewRequest(http.MethodGet, "/products?sort=name", nil), Options{})
+	j, err = json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"total":15,"per_page":10,"current_page":1,"last_page":2,"next_page":2,"prev_page":null,` +
+		`"links":{"self":"http://example.com/products?page=1\u0026per_page=10\u0026sort=name",` +
+		`"first":"http://example.com/products?page=1\u0026per_page=10\u0026sort=name",` +
+		`"last":"http://example.com/products?page=2\u0026per_page=10\u0026sort=name",` +
+		`"next":"http://example.com/products?page=2\u0026per_page=10\u0026sort=name"}}`
+	if string(j) != expected {
+		t.Errorf("expected %s got %s", expected, j)
+	}
+}
+
+func TestDialect_PageQuery(t *testing.T) {
+	paginator, err := NewPaginator(10, 3, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt := []struct {
+		name          string
+		dialect       Dialect
+		base          string
+		args          []interface{}
+		expectedQuery string
+	}{
+		{
+			name:          "MySQL",
+			dialect:       MySQL,
+			base:          "SELECT id FROM products WHERE colour = ? ORDER BY id",
+			args:          []interface{}{"red"},
+			expectedQuery: "SELECT id FROM products WHERE colour = ? ORDER BY id LIMIT ? OFFSET ?",
+		},
+		{
+			name:          "Postgres",
+			dialect:       Postgres,
+			base:          "SELECT id FROM products WHERE colour = $1 ORDER BY id",
+			args:          []interface{}{"red"},
+			expectedQuery: "SELECT id FROM products WHERE colour = $1 ORDER BY id LIMIT $2 OFFSET $3",
+		},
+		{
+			name:          "Postgres without arguments",
+			dialect:       Postgres,
+			base:          "SELECT id FROM products ORDER BY id",
+			expectedQuery: "SELECT id FROM products ORDER BY id LIMIT $1 OFFSET $2",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			query, args := tc.dialect.PageQuery(tc.base, tc.args, paginator)
+			if query != tc.expectedQuery {
+				t.Errorf("expected query %s got %s", tc.expectedQuery, query)
+			}
+			expectedArgs := append(append([]interface{}{}, tc.args...), 10, 20)
+			if !reflect.DeepEqual(args, expectedArgs) {
+				t.Errorf("expected args %v got %v", expectedArgs, args)
+			}
+			count := tc.dialect.CountQuery(tc.base)
+			if count != "SELECT COUNT(*) FROM ("+tc.base+") AS pagination_count" {
+				t.Errorf("unexpected count query %s", count)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	db, err := sql.Open("pagination_test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tt := []struct {
+		name             string
+		tx               bool
+		txOpts           *sql.TxOptions
+		page             int
+		expectedIDs      []int64
+		expectedLastPage int
+	}{
+		{
+			name:             "Query the first page",
+			page:             1,
+			expectedIDs:      []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			expectedLastPage: 3,
+		},
+		{
+			name:             "Query the last page in a transaction",
+			tx:               true,
+			page:             3,
+			expectedIDs:      []int64{20, 21, 22, 23, 24},
+			expectedLastPage: 3,
+		},
+		{
+			name:             "Query a page in a read-only transaction",
+			tx:               true,
+			txOpts:           &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true},
+			page:             2,
+			expectedIDs:      []int64{10, 11, 12, 13, 14, 15, 16, 17, 18, 19},
+			expectedLastPage: 3,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			paginator, err := NewPaginator(10, tc.page, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rows *Rows
+			if tc.tx {
+				rows, err = QueryTx(context.Background(), db, tc.txOpts, MySQL, paginator, "SELECT id FROM products ORDER BY id")
+				expected := driver.TxOptions{}
+				if tc.txOpts != nil {
+					expected = driver.TxOptions{Isolation: driver.IsolationLevel(tc.txOpts.Isolation), ReadOnly: tc.txOpts.ReadOnly}
+				}
+				if lastTxOptions != expected {
+					t.Errorf("expected the transaction options %+v got %+v", expected, lastTxOptions)
+				}
+			} else {
+				rows, err = Query(context.Background(), db, MySQL, paginator, "SELECT id FROM products ORDER BY id")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []int64
+			for rows.Next() {
+				var id int64
+				if err := rows.Scan(&id); err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, id)
+			}
+			if err := rows.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ids, tc.expectedIDs) {
+				t.Errorf("expected ids %v got %v", tc.expectedIDs, ids)
+			}
+			if paginator.GetTotal() != 25 || paginator.GetLastPage() != tc.expectedLastPage {
+				t.Errorf("expected 25 items on %d pages got %d on %d", tc.expectedLastPage, paginator.GetTotal(), paginator.GetLastPage())
+			}
+		})
+	}
+}
+
+func TestSlice(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	tt := []struct {
+		name             string
+		items            interface{}
+		page             int
+		total            int
+		expected         interface{}
+		expectedLastPage int
+		expErr           error
+	}{
+		{
+			name:             "First page",
+			items:            items,
+			page:             1,
+			expected:         []string{"a", "b"},
+			expectedLastPage: 3,
+		},
+		{
+			name:             "Last partial page",
+			items:            items,
+			page:             3,
+			expected:         []string{"e"},
+			expectedLastPage: 3,
+		},
+		{
+			name:             "Out of range page",
+			items:            items,
+			page:             4,
+			expected:         []string{},
+			expectedLastPage: 3,
+		},
+		{
+			name:             "Array",
+			items:            [3]int{1, 2, 3},
+			page:             2,
+			expected:         []int{3},
+			expectedLastPage: 2,
+		},
+		{
+			name:     "Empty slice",
+			items:    []int(nil),
+			page:     1,
+			expected: []int{},
+		},
+		{
+			name:     "Empty slice after a previous total",
+			items:    []int{},
+			page:     9,
+			total:    100,
+			expected: []int{},
+		},
+		{
+			name:   "Not a slice",
+			items:  "abc",
+			page:   1,
+			expErr: ErrNotSlice,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			paginator, err := NewPaginator(2, tc.page, tc.total)
+			if err != nil {
+				t.Fatal(err)
+			}
+			page, err := Slice(tc.items, paginator)
+			if err != tc.expErr {
+				t.Fatalf("expected error %v got %v", tc.expErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(page, tc.expected) {
+				t.Errorf("expected page %#v got %#v", tc.expected, page)
+			}
+			if paginator.GetTotal() != reflect.ValueOf(tc.items).Len() || paginator.GetLastPage() != tc.expectedLastPage {
+				t.Errorf("expected last page %d got total %d and last page %d", tc.expectedLastPage, paginator.GetTotal(), paginator.GetLastPage())
+			}
+			if resp := paginator.PrepareResponse(); (resp.NextPage != nil) != (tc.page < tc.expectedLastPage) {
+				t.Errorf("unexpected next page %v on page %d of %d", resp.NextPage, tc.page, tc.expectedLastPage)
+			}
+		})
+	}
+
+	page, err := Slice(items, &Paginator{perPage: 2, page: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page := append(page.([]string), "z"); items[2] != "c" || page[2] != "z" {
+		t.Errorf("expected appending to the page to leave the items unchanged got %v", items)
+	}
+}
+
+func init() {
+	sql.Register("pagination_test", testDriver{})
+}
+
+// testDriver serves a table of 25 products, answering count queries with
+// the total and page queries with the ids of the page.
+type testDriver struct{}
+
+func (testDriver) Open(string) (driver.Conn, error) { return testConn{}, nil }
+
+type testConn struct{}
+
+func (testConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (testConn) Close() error                        { return nil }
+func (testConn) Begin() (driver.Tx, error)           { return testConn{}, nil }
+func (testConn) Commit() error                       { return nil }
+func (testConn) Rollback() error                     { return nil }
+
+// lastTxOptions holds the options of the last transaction begun.
+var lastTxOptions driver.TxOptions
+
+func (testConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	lastTxOptions = opts
+	return testConn{}, nil
+}
+
+func (testConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if strings.HasPrefix(query, "SELECT COUNT(*)") {
+		return &testRows{values: []int64{25}}, nil
+	}
+	limit, offset := args[len(args)-2].Value.(int64), args[len(args)-1].Value.(int64)
+	rows := &testRows{}
+	for id := offset; id < offset+limit && id < 25; id++ {
+		rows.values = append(rows.values, id)
+	}
+	return rows, nil
+}
+
+type testRows struct {
+	values []int64
+}
+
+func (r *testRows) Columns() []string { return []string{"id"} }
+func (r *testRows) Close() error      { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
@@ -0,0 +1,118 @@
+package pagination
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultPageParam is the query parameter holding the page number.
+	DefaultPageParam = "page"
+
+	// DefaultPerPageParam is the query parameter holding the number of items
+	// per page.
+	DefaultPerPageParam = "per_page"
+
+	// DefaultPerPage is the number of items per page when none is requested.
+	DefaultPerPage = 10
+
+	// DefaultMaxPerPage is the largest number of items per page that can be
+	// requested.
+	DefaultMaxPerPage = 100
+)
+
+// Options configures how a Paginator is read from a request, and how the
+// links to its pages are built. Zero values fall back to the defaults above.
+type Options struct {
+	PageParam      string // Query parameter holding the page number.
+	PerPageParam   string // Query parameter holding the number of items per page.
+	DefaultPerPage int    // Number of items per page when none is requested.
+	MaxPerPage     int    // Largest number of items per page, larger requests are clamped.
+
+	// Whether links are built from the X-Forwarded-Proto and X-Forwarded-Host
+	// headers. Only set it behind a proxy that overwrites them.
+	TrustForwardedHeaders bool
+}
+
+// ParamError is returned when a pagination query parameter is not a positive
+// number.
+type ParamError struct {
+	Name  string // Name of the query parameter.
+	Value string // Value that was rejected.
+}
+
+// Error returns the error string naming the parameter and the bad value.
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid pagination parameter %s: %q", e.Name, e.Value)
+}
+
+// FromRequest returns a new Paginator for the page requested in the query of
+// the request, or a *ParamError when a parameter is not a positive number or
+// the page is too large to compute its offset.
+// A missing page starts at the first one, and a missing number of items per
+// page uses the default. The total is unknown at this point, so set it with
+// SetTotal once it has been counted.
+func FromRequest(r *http.Request, opts Options) (*Paginator, error) {
+	opts = opts.withDefaults()
+	query := r.URL.Query()
+
+	page, err := positiveParam(query, opts.PageParam, 1)
+	if err != nil {
+		return nil, err
+	}
+	perPage, err := positiveParam(query, opts.PerPageParam, opts.DefaultPerPage)
+	if err != nil {
+		return nil, err
+	}
+	if perPage > opts.MaxPerPage {
+		perPage = opts.MaxPerPage
+	}
+	// Refuse pages whose offset does not fit in an int.
+	if page-1 > maxInt/perPage {
+		return nil, &ParamError{Name: opts.PageParam, Value: query.Get(opts.PageParam)}
+	}
+
+	return NewPaginator(perPage, page, 0)
+}
+
+// withDefaults returns the options with every zero value replaced by its
+// default.
+func (o Options) withDefaults() Options {
+	if o.PageParam == "" {
+		o.PageParam = DefaultPageParam
+	}
+	if o.PerPageParam == "" {
+		o.PerPageParam = DefaultPerPageParam
+	}
+	if o.MaxPerPage <= 0 {
+		o.MaxPerPage = DefaultMaxPerPage
+	}
+	if o.DefaultPerPage <= 0 {
+		o.DefaultPerPage = DefaultPerPage
+	}
+	if o.DefaultPerPage > o.MaxPerPage {
+		o.DefaultPerPage = o.MaxPerPage
+	}
+	return o
+}
+
+// maxInt is the largest value of an int.
+const maxInt = int(^uint(0) >> 1)
+
+// positiveParam returns a query parameter as a positive number, or the
+// default when it is missing.
+func positiveParam(query url.Values, name string, def int) (int, error) {
+	values, ok := query[name]
+	if !ok || len(values) == 0 || values[0] == "" {
+		return def, nil
+	}
+	value := values[0]
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || i < 1 {
+		return 0, &ParamError{Name: name, Value: value}
+	}
+	return i, nil
+}
@@ -1,5 +1,7 @@
 package pagination
 
+import "net/http"
+
 // Response represents a pagination response.
 type Response struct {
 	Total       int  `json:"total"`        // The total number of items.
@@ -8,6 +10,15 @@ type Response struct {
 	LastPage    int  `json:"last_page"`    // The number of the last possible page.
 	NextPage    *int `json:"next_page"`    // The number of the next page (if possible).
 	PrevPage    *int `json:"prev_page"`    // The number of the previous page (if possible).
+
+	Links *Links `json:"links,omitempty"` // The URLs of the pages (optional).
+}
+
+// SetLinks sets the absolute URLs of the current, first, last, next and
+// previous pages, built from the URL of the request with its page and per
+// page query parameters replaced and every other parameter kept.
+func (r *Response) SetLinks(req *http.Request, opts Options) {
+	r.Links = NewLinks(RequestURL(req, opts), r, opts)
 }
 
 // newResponse returns a new paginated Response for a microservice endpoint.
@@ -33,3 +44,10 @@ func newResponse(total, perPage, currentPage, lastPage int) *Response {
 
 	return r
 }
+
+// CursorResponse represents a cursor pagination response.
+type CursorResponse struct {
+	PerPage    int     `json:"per_page"`    // Number of items displayed per page.
+	NextCursor *string `json:"next_cursor"` // The cursor of the next page (if possible).
+	PrevCursor *string `json:"prev_cursor"` // The cursor of the previous page (if possible).
+}
@@ -0,0 +1,51 @@
+package pagination
+
+import (
+	"reflect"
+)
+
+// Slice sets the total of the paginator to the length of the items, which
+// must be a slice or an array, and returns the slice of items on the current
+// page. The page is empty when it is out of range, never nil, so it encodes
+// as an empty JSON array:
+//
+//	page, err := pagination.Slice(products, paginator)
+//	if err != nil {
+//	    return err
+//	}
+//	data := &response.Data{Type: "products", Content: page}
+//	resp := response.NewPaginated(paginator, http.StatusOK, "", data)
+func Slice(items interface{}, p *Paginator) (interface{}, error) {
+	v := reflect.ValueOf(items)
+	switch v.Kind() {
+	case reflect.Slice:
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		v = copied.Slice(0, v.Len())
+	default:
+		return nil, ErrNotSlice
+	}
+
+	if p.GetPerPage() < 0 {
+		return nil, ErrInvalidPerPage
+	}
+	if err := p.SetTotal(v.Len()); err != nil {
+		return nil, err
+	}
+	if v.Len() == 0 {
+		// SetTotal keeps the last page of a previous total when there are
+		// no items, which would advertise pages that do not exist.
+		p.lastPage = 0
+	}
+
+	start := p.GetOffset()
+	if start < 0 || start >= v.Len() {
+		return reflect.MakeSlice(v.Type(), 0, 0).Interface(), nil
+	}
+	end := start + p.GetPerPage()
+	if end > v.Len() {
+		end = v.Len()
+	}
+	return v.Slice3(start, end, end).Interface(), nil
+}
@@ -0,0 +1,106 @@
+package pagination
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Dialect describes the placeholder style of a database.
+type Dialect int
+
+const (
+	// MySQL uses ? placeholders.
+	MySQL Dialect = iota
+
+	// Postgres uses numbered $1 placeholders.
+	Postgres
+)
+
+// Querier runs queries, and is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Rows holds the rows of a page. Closing them commits the transaction they
+// were read in, if any.
+type Rows struct {
+	*sql.Rows
+	tx *sql.Tx
+}
+
+// Close closes the rows and commits the transaction they were read in.
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	if r.tx == nil {
+		return err
+	}
+	if commitErr := r.tx.Commit(); err == nil && commitErr != sql.ErrTxDone {
+		err = commitErr
+	}
+	return err
+}
+
+// CountQuery returns a query counting the rows returned by the base query.
+func (d Dialect) CountQuery(base string) string {
+	return "SELECT COUNT(*) FROM (" + base + ") AS pagination_count"
+}
+
+// PageQuery returns the base query limited to the current page of the
+// paginator, along with its arguments followed by the limit and offset.
+func (d Dialect) PageQuery(base string, args []interface{}, p *Paginator) (string, []interface{}) {
+	pageArgs := append(append([]interface{}{}, args...), p.GetPerPage(), p.GetOffset())
+	if d == Postgres {
+		return fmt.Sprintf("%s LIMIT $%d OFFSET $%d", base, len(args)+1, len(args)+2), pageArgs
+	}
+	return base + " LIMIT ? OFFSET ?", pageArgs
+}
+
+// Query counts the rows of the base query to set the total of the paginator,
+// and returns the rows of its current page. The base query has to order its
+// rows, so pages do not overlap:
+//
+//	rows, err := pagination.Query(ctx, db, pagination.Postgres, paginator,
+//	    "SELECT id, name FROM products WHERE colour = $1 ORDER BY id", colour)
+func Query(ctx context.Context, q Querier, d Dialect, p *Paginator, base string, args ...interface{}) (*Rows, error) {
+	var total int
+	if err := q.QueryRowContext(ctx, d.CountQuery(base), args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("cannot count rows: %v", err)
+	}
+	if err := p.SetTotal(total); err != nil {
+		return nil, err
+	}
+
+	query, pageArgs := d.PageQuery(base, args, p)
+	rows, err := q.QueryContext(ctx, query, pageArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query page: %v", err)
+	}
+	return &Rows{Rows: rows}, nil
+}
+
+// QueryTx is like Query, but runs both queries in a transaction begun with
+// the options, or the driver defaults when nil, so the total can match the
+// rows of the page. Databases only guarantee it from the repeatable read
+// isolation level:
+//
+//	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+//	rows, err := pagination.QueryTx(ctx, db, opts, pagination.MySQL, paginator,
+//	    "SELECT id, name FROM products ORDER BY id")
+//
+// The transaction is committed when the rows are closed.
+func QueryTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, d Dialect, p *Paginator, base string, args ...interface{}) (*Rows, error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot begin transaction: %v", err)
+	}
+
+	rows, err := Query(ctx, tx, d, p, base, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	rows.tx = tx
+	return rows, nil
+}
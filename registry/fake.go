@@ -0,0 +1,130 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+
+	microservicecore "github.com/LUSHDigital/microservice-core-golang"
+	"github.com/LUSHDigital/microservice-core-golang/response"
+)
+
+// Fake - In-process service registry for tests. It can be used as a Client
+// directly, or served with httptest to back an HTTPClient.
+type Fake struct {
+	mu         sync.Mutex
+	services   map[string]*microservicecore.MicroserviceInfo
+	heartbeats map[string]int
+	handler    http.Handler
+}
+
+// NewFake - Prepare a new, empty fake registry.
+func NewFake() *Fake {
+	f := &Fake{
+		services:   map[string]*microservicecore.MicroserviceInfo{},
+		heartbeats: map[string]int{},
+	}
+
+	// The routes are fixed and known to be valid.
+	f.handler, _ = microservicecore.NewRouter(nil, []microservicecore.Route{
+		{Path: "/services", Method: http.MethodPost, Handler: f.handle(f.Register)},
+		{Path: "/services/{name}/heartbeat", Method: http.MethodPut, Handler: f.handle(f.Heartbeat)},
+		{Path: "/services/{name}", Method: http.MethodDelete, Handler: f.handle(f.Deregister)},
+	})
+	return f
+}
+
+// Register - Add the microservice to the registry.
+func (f *Fake) Register(ctx context.Context, info *microservicecore.MicroserviceInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	registered := *info
+	f.services[info.ServiceName] = &registered
+	return nil
+}
+
+// Heartbeat - Tell the registry the microservice is still running.
+func (f *Fake) Heartbeat(ctx context.Context, info *microservicecore.MicroserviceInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.services[info.ServiceName]; !ok {
+		return NotRegisteredError{ServiceName: info.ServiceName}
+	}
+	f.heartbeats[info.ServiceName]++
+	return nil
+}
+
+// Deregister - Remove the microservice from the registry.
+func (f *Fake) Deregister(ctx context.Context, info *microservicecore.MicroserviceInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.services[info.ServiceName]; !ok {
+		return NotRegisteredError{ServiceName: info.ServiceName}
+	}
+	delete(f.services, info.ServiceName)
+	return nil
+}
+
+// Service - Get a registered microservice by name.
+func (f *Fake) Service(name string) (*microservicecore.MicroserviceInfo, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, ok := f.services[name]
+	return info, ok
+}
+
+// Services - Get every registered microservice, ordered by name.
+func (f *Fake) Services() []*microservicecore.MicroserviceInfo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	services := make([]*microservicecore.MicroserviceInfo, 0, len(f.services))
+	for _, info := range f.services {
+		services = append(services, info)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].ServiceName < services[j].ServiceName
+	})
+	return services
+}
+
+// Heartbeats - Get how many heartbeats a microservice has sent.
+func (f *Fake) Heartbeats(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.heartbeats[name]
+}
+
+// ServeHTTP - Serve the protocol HTTPClient speaks.
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.handler.ServeHTTP(w, r)
+}
+
+// handle - Adapt a Client method to an HTTP handler.
+func (f *Fake) handle(action func(context.Context, *microservicecore.MicroserviceInfo) error) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var info microservicecore.MicroserviceInfo
+		if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+			response.JSONError(err).WriteTo(w)
+			return
+		}
+		if name := microservicecore.PathParams(r).String("name"); name != "" {
+			info.ServiceName = name
+		}
+
+		switch err := action(r.Context(), &info).(type) {
+		case nil:
+			response.New(http.StatusOK, "", nil).WriteTo(w)
+		case NotRegisteredError:
+			response.NotFoundErr(err.Error()).WriteTo(w)
+		default:
+			response.InternalError(err).WriteTo(w)
+		}
+	}
+}
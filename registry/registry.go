@@ -0,0 +1,186 @@
+// Package registry publishes information about a microservice to a service
+// registry, so the registry can keep track of every running service.
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	microservicecore "github.com/LUSHDigital/microservice-core-golang"
+	"github.com/LUSHDigital/microservice-core-golang/response"
+)
+
+// DefaultHeartbeatInterval - How often a registration is refreshed when no
+// interval is provided.
+const DefaultHeartbeatInterval = 30 * time.Second
+
+// Client - Publishes information about a microservice to a service registry.
+type Client interface {
+	// Register - Add the microservice to the registry.
+	Register(ctx context.Context, info *microservicecore.MicroserviceInfo) error
+
+	// Heartbeat - Tell the registry the microservice is still running.
+	Heartbeat(ctx context.Context, info *microservicecore.MicroserviceInfo) error
+
+	// Deregister - Remove the microservice from the registry.
+	Deregister(ctx context.Context, info *microservicecore.MicroserviceInfo) error
+}
+
+// NotRegisteredError - Error returned when the registry does not know about
+// the microservice, such as after the registry has restarted.
+type NotRegisteredError struct {
+	ServiceName string
+}
+
+// Error - Error string for a service the registry does not know about.
+func (e NotRegisteredError) Error() string {
+	return fmt.Sprintf("service (%s) is not registered", e.ServiceName)
+}
+
+// HTTPClient - Client talking to a registry over HTTP.
+//
+// The info document is POSTed to {URL}/services to register, PUT to
+// {URL}/services/{name}/heartbeat to heartbeat, and {URL}/services/{name} is
+// DELETEd to deregister.
+type HTTPClient struct {
+	URL    string       // Base URL of the registry.
+	Client *http.Client // http client implementation
+}
+
+// NewHTTPClient - Prepare a new HTTPClient for the registry at the provided URL.
+func NewHTTPClient(client *http.Client, registryURL string) *HTTPClient {
+	return &HTTPClient{
+		URL:    strings.TrimSuffix(registryURL, "/"),
+		Client: client,
+	}
+}
+
+// Register - Add the microservice to the registry.
+func (c *HTTPClient) Register(ctx context.Context, info *microservicecore.MicroserviceInfo) error {
+	return c.do(ctx, http.MethodPost, "services", info)
+}
+
+// Heartbeat - Tell the registry the microservice is still running.
+func (c *HTTPClient) Heartbeat(ctx context.Context, info *microservicecore.MicroserviceInfo) error {
+	return c.do(ctx, http.MethodPut, "services/"+url.PathEscape(info.ServiceName)+"/heartbeat", info)
+}
+
+// Deregister - Remove the microservice from the registry.
+func (c *HTTPClient) Deregister(ctx context.Context, info *microservicecore.MicroserviceInfo) error {
+	return c.do(ctx, http.MethodDelete, "services/"+url.PathEscape(info.ServiceName), info)
+}
+
+// do - Send the info document to a registry resource.
+func (c *HTTPClient) do(ctx context.Context, method, resource string, info *microservicecore.MicroserviceInfo) error {
+	body, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("cannot encode json: %v", err)
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/%s", c.URL, resource), bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("cannot build registry request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("cannot perform registry request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return NotRegisteredError{ServiceName: info.ServiceName}
+	case resp.StatusCode >= http.StatusBadRequest:
+		serviceResponse := response.Response{}
+		content, err := ioutil.ReadAll(resp.Body)
+		if err == nil {
+			json.Unmarshal(content, &serviceResponse)
+		}
+		return fmt.Errorf("registry request failed with status %d: %s", resp.StatusCode, serviceResponse.Message)
+	}
+	return nil
+}
+
+// Registration - A microservice kept registered by heartbeating in the background.
+type Registration struct {
+	client   Client
+	info     *microservicecore.MicroserviceInfo
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Register - Register the microservice and keep heartbeating on the provided
+// interval until Stop is called. A zero interval means DefaultHeartbeatInterval.
+func Register(ctx context.Context, client Client, info *microservicecore.MicroserviceInfo, interval time.Duration) (*Registration, error) {
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	if err := client.Register(ctx, info); err != nil {
+		return nil, fmt.Errorf("cannot register service: %v", err)
+	}
+
+	r := &Registration{
+		client:   client,
+		info:     info,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go r.heartbeat()
+	return r, nil
+}
+
+// heartbeat - Heartbeat until stopped, registering again if the registry has
+// forgotten about the microservice.
+func (r *Registration) heartbeat() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+		err := r.client.Heartbeat(ctx, r.info)
+		if _, ok := err.(NotRegisteredError); ok {
+			err = r.client.Register(ctx, r.info)
+		}
+		cancel()
+
+		if err != nil {
+			log.Printf("cannot heartbeat service registry: %v", err)
+		}
+	}
+}
+
+// Stop - Stop heartbeating and deregister the microservice. It can be passed
+// straight to Server.OnShutdown.
+func (r *Registration) Stop(ctx context.Context) error {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+	<-r.done
+
+	if err := r.client.Deregister(ctx, r.info); err != nil {
+		return fmt.Errorf("cannot deregister service: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	microservicecore "github.com/LUSHDigital/microservice-core-golang"
+)
+
+// exampleInfo - Information about an example microservice.
+var exampleInfo = &microservicecore.MicroserviceInfo{
+	ServiceName:    "example-service",
+	ServiceType:    "examples",
+	ServiceScope:   "testing",
+	ServiceVersion: "0.0.1",
+	Endpoints: []microservicecore.Route{
+		{Path: "/products", Method: http.MethodGet},
+	},
+}
+
+func TestHTTPClient(t *testing.T) {
+	fake := NewFake()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := NewHTTPClient(server.Client(), server.URL+"/")
+	ctx := context.Background()
+
+	if err := client.Heartbeat(ctx, exampleInfo); err == nil {
+		t.Error("expected heartbeating an unregistered service to fail")
+	} else if _, ok := err.(NotRegisteredError); !ok {
+		t.Errorf("expected NotRegisteredError got (%T) %v", err, err)
+	}
+
+	if err := client.Register(ctx, exampleInfo); err != nil {
+		t.Fatal(err)
+	}
+	registered, ok := fake.Service(exampleInfo.ServiceName)
+	if !ok {
+		t.Fatal("expected the service to be registered")
+	}
+	if registered.ServiceVersion != exampleInfo.ServiceVersion || len(registered.Endpoints) != 1 {
+		t.Errorf("expected %+v got %+v", exampleInfo, registered)
+	}
+
+	if err := client.Heartbeat(ctx, exampleInfo); err != nil {
+		t.Fatal(err)
+	}
+	if fake.Heartbeats(exampleInfo.ServiceName) != 1 {
+		t.Errorf("expected 1 heartbeat got %d", fake.Heartbeats(exampleInfo.ServiceName))
+	}
+
+	if err := client.Deregister(ctx, exampleInfo); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.Services()) != 0 {
+		t.Errorf("expected no services got %d", len(fake.Services()))
+	}
+
+	escaped := *exampleInfo
+	escaped.ServiceName = "team/products v2?"
+	if err := client.Register(ctx, &escaped); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Heartbeat(ctx, &escaped); err != nil {
+		t.Fatalf("expected the service name to be escaped: %v", err)
+	}
+	if fake.Heartbeats(escaped.ServiceName) != 1 {
+		t.Errorf("expected 1 heartbeat for %q got %d", escaped.ServiceName, fake.Heartbeats(escaped.ServiceName))
+	}
+}
+
+func TestRegister(t *testing.T) {
+	fake := NewFake()
+	registration, err := Register(context.Background(), fake, exampleInfo, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate the registry forgetting about the service.
+	fake.Deregister(context.Background(), exampleInfo)
+
+	deadline := time.Now().Add(time.Second)
+	for fake.Heartbeats(exampleInfo.ServiceName) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if fake.Heartbeats(exampleInfo.ServiceName) < 2 {
+		t.Fatal("expected the registration to recover and keep heartbeating")
+	}
+
+	if err := registration.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fake.Service(exampleInfo.ServiceName); ok {
+		t.Error("expected the service to be deregistered")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/LUSHDigital/microservice-core-golang/pagination"
@@ -108,6 +109,19 @@ func ParamError(name string) *Response {
 	return New(http.StatusUnprocessableEntity, fmt.Sprintf("invalid or missing parameter: %v", name), nil)
 }
 
+// PaginatorFromRequest returns a new Paginator for the page requested in the query of the
+// request, like pagination.FromRequest, or a prepared ParamError response naming the bad parameter.
+func PaginatorFromRequest(r *http.Request, opts pagination.Options) (*pagination.Paginator, *Response) {
+	paginator, err := pagination.FromRequest(r, opts)
+	if paramErr, ok := err.(*pagination.ParamError); ok {
+		return nil, ParamError(paramErr.Name)
+	}
+	if err != nil {
+		return nil, InternalError(err)
+	}
+	return paginator, nil
+}
+
 // ValidationError returns a prepared 422 Unprocessable Entity response, including the name of
 // the failing validation/validator in the message field of the response object.
 func ValidationError(err error, name string) *Response {
@@ -182,11 +196,14 @@ func (r *Response) GetCode() int {
 
 // PaginatedResponse - A paginated response format for a microservice.
 type PaginatedResponse struct {
-	Status     string               `json:"status"`         // Can be 'ok' or 'fail'
-	Code       int                  `json:"code"`           // Any valid HTTP response code
-	Message    string               `json:"message"`        // Any relevant message (optional)
-	Data       *Data                `json:"data,omitempty"` // Data to pass along to the response (optional)
-	Pagination *pagination.Response `json:"pagination"`     // Pagination data
+	Status     string                     `json:"status"`               // Can be 'ok' or 'fail'
+	Code       int                        `json:"code"`                 // Any valid HTTP response code
+	Message    string                     `json:"message"`              // Any relevant message (optional)
+	Data       *Data                      `json:"data,omitempty"`       // Data to pass along to the response (optional)
+	Pagination *pagination.Response       `json:"pagination,omitempty"` // Page number pagination data
+	Cursor     *pagination.CursorResponse `json:"cursor,omitempty"`     // Cursor pagination data
+
+	links *pagination.Links // Links to emit as headers (optional)
 }
 
 // NewPaginated returns a new PaginatedResponse for a microservice endpoint
@@ -207,9 +224,44 @@ func NewPaginated(paginator *pagination.Paginator, code int, message string, dat
 	}
 }
 
+// NewCursorPaginated returns a new PaginatedResponse for a microservice
+// endpoint using cursor pagination, returning the cursors under "cursor"
+// rather than page numbers under "pagination".
+func NewCursorPaginated(paginator *pagination.CursorPaginator, code int, message string, data *Data) *PaginatedResponse {
+	var status string
+	switch {
+	case code >= http.StatusOK && code < http.StatusBadRequest:
+		status = StatusOk
+	default:
+		status = StatusFail
+	}
+	return &PaginatedResponse{
+		Code:    code,
+		Status:  status,
+		Message: message,
+		Data:    data,
+		Cursor:  paginator.PrepareResponse(),
+	}
+}
+
+// WithLinks - Emit pagination headers when the response is written: a Link header pointing at
+// the first, last, next and previous pages, and an X-Total-Count header. The links are built from
+// the URL of the request, keeping its other query parameters, with the page parameters named as in
+// the options.
+func (p *PaginatedResponse) WithLinks(r *http.Request, opts pagination.Options) *PaginatedResponse {
+	if p.Pagination != nil {
+		p.links = pagination.NewLinks(pagination.RequestURL(r, opts), p.Pagination, opts)
+	}
+	return p
+}
+
 // WriteTo - pick a response writer to write the default json response to.
 func (p *PaginatedResponse) WriteTo(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
+	if p.links != nil {
+		w.Header().Set("Link", p.links.Header())
+		w.Header().Set("X-Total-Count", strconv.Itoa(p.Pagination.Total))
+	}
 	w.WriteHeader(p.Code)
 
 	// Don't attempt to write a body for 204s.
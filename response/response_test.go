@@ -167,6 +167,73 @@ func TestPaginatedResponse_ExtractData(t *testing.T) {
 	}
 }
 
+func TestNewCursorPaginated(t *testing.T) {
+	paginator, err := pagination.NewCursorPaginator([]byte("secret"), 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := paginator.SetResults([]interface{}{1}, []interface{}{2}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	NewCursorPaginated(paginator, http.StatusOK, "", preparedData).WriteTo(w)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body["pagination"]; ok {
+		t.Errorf("TestNewCursorPaginated: expected no page number pagination got %v", body["pagination"])
+	}
+	cursor, ok := body["cursor"].(map[string]interface{})
+	if !ok || cursor["next_cursor"] == nil || cursor["prev_cursor"] != nil || cursor["per_page"] != float64(2) {
+		t.Errorf("TestNewCursorPaginated: unexpected cursor %v", body["cursor"])
+	}
+}
+
+func TestPaginatorFromRequest(t *testing.T) {
+	paginator, resp := PaginatorFromRequest(httptest.NewRequest(http.MethodGet, "/tests?page=2", nil), pagination.Options{})
+	if resp != nil || paginator.GetPage() != 2 {
+		t.Errorf("TestPaginatorFromRequest: expected page 2 got %v (%v)", paginator, resp)
+	}
+
+	paginator, resp = PaginatorFromRequest(httptest.NewRequest(http.MethodGet, "/tests?per_page=-5", nil), pagination.Options{})
+	if paginator != nil || resp == nil {
+		t.Fatalf("TestPaginatorFromRequest: expected an error response got %v", paginator)
+	}
+	if expected := ParamError("per_page"); !reflect.DeepEqual(resp, expected) {
+		t.Errorf("TestPaginatorFromRequest: expected %v got %v", expected, resp)
+	}
+}
+
+func TestPaginatedResponse_WithLinks(t *testing.T) {
+	paginator, err := pagination.NewPaginator(10, 1, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/tests?q=soap", nil)
+
+	w := httptest.NewRecorder()
+	NewPaginated(paginator, http.StatusOK, "", preparedData).WithLinks(r, pagination.Options{}).WriteTo(w)
+
+	expectedLink := `<http://example.com/tests?page=2&per_page=10&q=soap>; rel="next", ` +
+		`<http://example.com/tests?page=1&per_page=10&q=soap>; rel="first", ` +
+		`<http://example.com/tests?page=3&per_page=10&q=soap>; rel="last"`
+	if link := w.Header().Get("Link"); link != expectedLink {
+		t.Errorf("TestPaginatedResponse_WithLinks: expected Link %s got %s", expectedLink, link)
+	}
+	if total := w.Header().Get("X-Total-Count"); total != "25" {
+		t.Errorf("TestPaginatedResponse_WithLinks: expected X-Total-Count 25 got %s", total)
+	}
+
+	w = httptest.NewRecorder()
+	NewPaginated(paginator, http.StatusOK, "", preparedData).WriteTo(w)
+	if link := w.Header().Get("Link"); link != "" {
+		t.Errorf("TestPaginatedResponse_WithLinks: expected no Link header without links got %s", link)
+	}
+}
+
 func TestData_MarshalJSON(t *testing.T) {
 	tt := []struct {
 		name string
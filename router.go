@@ -0,0 +1,413 @@
+package microservicecore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/LUSHDigital/microservice-core-golang/response"
+	"github.com/LUSHDigital/microservice-core-golang/transport/config"
+)
+
+// contextKey - Type for keys of values this package stores in a request context.
+type contextKey int
+
+const (
+	// paramsKey - Context key for the path parameters of the matched route.
+	paramsKey contextKey = iota
+
+	// versionKey - Context key for the API version the request was dispatched for.
+	versionKey
+)
+
+// Params - Path parameters extracted from a templated route path.
+type Params map[string]string
+
+// PathParams - Get the path parameters the router extracted for a request.
+func PathParams(r *http.Request) Params {
+	params, _ := r.Context().Value(paramsKey).(Params)
+	return params
+}
+
+// String - Get a parameter as a string, or an empty string if it is not set.
+func (p Params) String(name string) string {
+	return p[name]
+}
+
+// Int - Get a parameter as an int.
+func (p Params) Int(name string) (int, error) {
+	value, ok := p[name]
+	if !ok {
+		return 0, fmt.Errorf("path parameter (%s) has not been set", name)
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("path parameter (%s) is not an integer: %q", name, value)
+	}
+	return i, nil
+}
+
+// Int64 - Get a parameter as an int64.
+func (p Params) Int64(name string) (int64, error) {
+	value, ok := p[name]
+	if !ok {
+		return 0, fmt.Errorf("path parameter (%s) has not been set", name)
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("path parameter (%s) is not an integer: %q", name, value)
+	}
+	return i, nil
+}
+
+// segment - A single part of a route path template.
+type segment struct {
+	value string // Literal value, or parameter name.
+	param bool   // Whether the segment captures a parameter.
+}
+
+// pattern - A parsed route path template and the routes registered on it.
+type pattern struct {
+	template string
+	segments []segment
+	routes   []Route
+}
+
+// parsePattern - Parse a route path such as /products/{id}.
+func parsePattern(path string) (*pattern, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path %q must start with a slash", path)
+	}
+
+	p := &pattern{template: path}
+	seen := map[string]bool{}
+	for _, part := range splitPath(path) {
+		if !strings.HasPrefix(part, "{") && !strings.HasSuffix(part, "}") {
+			if strings.ContainsAny(part, "{}") {
+				return nil, fmt.Errorf("path %q has a malformed segment %q", path, part)
+			}
+			p.segments = append(p.segments, segment{value: part})
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(part, "{"), "}")
+		if name == "" || len(name) != len(part)-2 || strings.ContainsAny(name, "{}") {
+			return nil, fmt.Errorf("path %q has a malformed segment %q", path, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("path %q uses the parameter %q more than once", path, name)
+		}
+		seen[name] = true
+		p.segments = append(p.segments, segment{value: name, param: true})
+	}
+	return p, nil
+}
+
+// match - Match a request path against the pattern, returning its parameters.
+func (p *pattern) match(parts []string) (Params, bool) {
+	if len(parts) != len(p.segments) {
+		return nil, false
+	}
+
+	params := Params{}
+	for i, seg := range p.segments {
+		value, err := url.PathUnescape(parts[i])
+		if err != nil {
+			return nil, false
+		}
+		if !seg.param {
+			if seg.value != value {
+				return nil, false
+			}
+			continue
+		}
+		params[seg.value] = value
+	}
+	return params, true
+}
+
+// sameShape - Whether two patterns match exactly the same set of paths.
+func (p *pattern) sameShape(other *pattern) bool {
+	if len(p.segments) != len(other.segments) {
+		return false
+	}
+	for i, seg := range p.segments {
+		if seg.param != other.segments[i].param {
+			return false
+		}
+		if !seg.param && seg.value != other.segments[i].value {
+			return false
+		}
+	}
+	return true
+}
+
+// moreSpecific - Whether the pattern should win over another matching one.
+// Literal segments beat parameters, reading from left to right.
+func (p *pattern) moreSpecific(other *pattern) bool {
+	for i, seg := range p.segments {
+		if seg.param != other.segments[i].param {
+			return !seg.param
+		}
+	}
+	return false
+}
+
+// has - Whether a route registered for exactly the provided method serves
+// any of the versions of another route.
+func (p *pattern) has(route Route) bool {
+	for _, existing := range p.routes {
+		if existing.Method != route.Method {
+			continue
+		}
+		if len(existing.Versions) == 0 && len(route.Versions) == 0 {
+			return true
+		}
+		for _, version := range route.Versions {
+			if existing.servesVersion(version) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// methodRoutes - Find the routes registered for a method, falling back from HEAD to GET.
+func (p *pattern) methodRoutes(method string) []Route {
+	var routes []Route
+	for _, route := range p.routes {
+		if route.Method == method {
+			routes = append(routes, route)
+		}
+	}
+	if len(routes) == 0 && method == http.MethodHead {
+		return p.methodRoutes(http.MethodGet)
+	}
+	return routes
+}
+
+// allow - Get the value of the Allow header for the pattern.
+func (p *pattern) allow() string {
+	seen := map[string]bool{}
+	var methods []string
+	for _, route := range p.routes {
+		if seen[route.Method] {
+			continue
+		}
+		seen[route.Method] = true
+		methods = append(methods, route.Method)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
+// servesVersion - Whether the route declares the provided major version.
+func (route Route) servesVersion(version int) bool {
+	for _, v := range route.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestVersion - Get the major API version the router dispatched a request
+// for, or zero if the request was dispatched to an unversioned route.
+func RequestVersion(r *http.Request) int {
+	version, _ := r.Context().Value(versionKey).(int)
+	return version
+}
+
+// selectVersion - Pick the route serving the version requested through the
+// service version header. Without the header, an unversioned route is
+// preferred, followed by the route serving the highest version. With it, a
+// route declaring the version is preferred, followed by an unversioned route.
+func selectVersion(routes []Route, header string) (Route, int, error) {
+	var (
+		fallback    *Route
+		latest      *Route
+		latestV     int
+		supported   []int
+		requested   int
+		isRequested = header != ""
+	)
+	if len(routes) == 1 && len(routes[0].Versions) == 0 {
+		return routes[0], 0, nil
+	}
+	if isRequested {
+		v, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(header), "v"))
+		if err != nil || v <= 0 {
+			return Route{}, 0, fmt.Errorf("invalid service version %q", header)
+		}
+		requested = v
+	}
+
+	for i, route := range routes {
+		if len(route.Versions) == 0 {
+			fallback = &routes[i]
+			continue
+		}
+		for _, v := range route.Versions {
+			if isRequested && v == requested {
+				return route, v, nil
+			}
+			if v > latestV {
+				latest, latestV = &routes[i], v
+			}
+			supported = append(supported, v)
+		}
+	}
+
+	switch {
+	case fallback != nil:
+		return *fallback, 0, nil
+	case !isRequested && latest != nil:
+		return *latest, latestV, nil
+	}
+
+	sort.Ints(supported)
+	versions := make([]string, len(supported))
+	for i, v := range supported {
+		versions[i] = strconv.Itoa(v)
+	}
+	return Route{}, 0, fmt.Errorf("unsupported service version %d, supported versions: %s", requested, strings.Join(versions, ", "))
+}
+
+// Router - Dispatches requests to a set of routes.
+type Router struct {
+	mu       sync.RWMutex
+	info     *MicroserviceInfo
+	patterns []*pattern
+}
+
+// NewRouter - Prepare a router serving the provided routes. When info is not
+// nil, its endpoints are kept in sync with every route the router serves.
+func NewRouter(info *MicroserviceInfo, routes []Route) (*Router, error) {
+	r := &Router{info: info}
+	if info != nil {
+		info.Endpoints = nil
+	}
+	for _, route := range routes {
+		if err := r.Handle(route); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Handle - Register a route with the router.
+func (r *Router) Handle(route Route) error {
+	if route.Handler == nil {
+		return fmt.Errorf("cannot register route %s %s: missing handler", route.Method, route.Path)
+	}
+	if route.Method == "" {
+		return fmt.Errorf("cannot register route %s: missing method", route.Path)
+	}
+	route.Method = strings.ToUpper(route.Method)
+
+	p, err := parsePattern(route.Path)
+	if err != nil {
+		return fmt.Errorf("cannot register route %s %s: %v", route.Method, route.Path, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.patterns {
+		if !existing.sameShape(p) {
+			continue
+		}
+		if existing.template != p.template {
+			return fmt.Errorf("cannot register route %s %s: conflicts with %s", route.Method, route.Path, existing.template)
+		}
+		if existing.has(route) {
+			return fmt.Errorf("cannot register route %s %s: already registered", route.Method, route.Path)
+		}
+		p = existing
+		break
+	}
+	if len(p.routes) == 0 {
+		r.patterns = append(r.patterns, p)
+	}
+	p.routes = append(p.routes, route)
+
+	if r.info != nil {
+		r.info.Endpoints = append(r.info.Endpoints, route)
+	}
+	return nil
+}
+
+// Routes - Get every route registered with the router.
+func (r *Router) Routes() []Route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var routes []Route
+	for _, p := range r.patterns {
+		routes = append(routes, p.routes...)
+	}
+	return routes
+}
+
+// ServeHTTP - Dispatch a request to the matching route.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	p, params := r.lookup(req.URL.EscapedPath())
+	if p == nil {
+		response.NotFoundErr(fmt.Sprintf("no route found for %s", req.URL.Path)).WriteTo(w)
+		return
+	}
+
+	routes := p.methodRoutes(req.Method)
+	if len(routes) == 0 {
+		w.Header().Set("Allow", p.allow())
+		response.New(http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed for %s", req.Method, req.URL.Path), nil).WriteTo(w)
+		return
+	}
+
+	route, version, err := selectVersion(routes, req.Header.Get(config.ServiceVersionHeader))
+	if err != nil {
+		response.New(http.StatusNotAcceptable, err.Error(), nil).WriteTo(w)
+		return
+	}
+
+	ctx := context.WithValue(req.Context(), paramsKey, params)
+	ctx = context.WithValue(ctx, versionKey, version)
+	route.Handler(w, req.WithContext(ctx))
+}
+
+// lookup - Find the most specific pattern matching a path.
+func (r *Router) lookup(path string) (*pattern, Params) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	parts := splitPath(path)
+
+	var (
+		best       *pattern
+		bestParams Params
+	)
+	for _, p := range r.patterns {
+		params, ok := p.match(parts)
+		if !ok {
+			continue
+		}
+		if best == nil || p.moreSpecific(best) {
+			best, bestParams = p, params
+		}
+	}
+	return best, bestParams
+}
+
+// splitPath - Split a path into its segments, ignoring leading and trailing slashes.
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
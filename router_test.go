@@ -0,0 +1,329 @@
+package microservicecore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LUSHDigital/microservice-core-golang/response"
+	"github.com/LUSHDigital/microservice-core-golang/transport/config"
+)
+
+// echoRoute - Route writing its path template and parameters back.
+func echoRoute(method, path string) Route {
+	return Route{
+		Path:   path,
+		Method: method,
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s %v", path, map[string]string(PathParams(r)))
+		},
+	}
+}
+
+func TestRouter_ServeHTTP(t *testing.T) {
+	router, err := NewRouter(nil, []Route{
+		echoRoute(http.MethodGet, "/products"),
+		echoRoute(http.MethodPost, "/products"),
+		echoRoute(http.MethodGet, "/products/{id}"),
+		echoRoute(http.MethodGet, "/products/featured"),
+		echoRoute(http.MethodGet, "/products/{id}/reviews/{review}"),
+		echoRoute(http.MethodGet, "/café/{x}"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		name          string
+		method        string
+		path          string
+		expectedCode  int
+		expectedBody  string
+		expectedAllow string
+	}{
+		{
+			name:         "static path",
+			method:       http.MethodGet,
+			path:         "/products",
+			expectedCode: http.StatusOK,
+			expectedBody: "/products map[]",
+		},
+		{
+			name:         "trailing slash",
+			method:       http.MethodPost,
+			path:         "/products/",
+			expectedCode: http.StatusOK,
+			expectedBody: "/products map[]",
+		},
+		{
+			name:         "path parameter",
+			method:       http.MethodGet,
+			path:         "/products/42",
+			expectedCode: http.StatusOK,
+			expectedBody: "/products/{id} map[id:42]",
+		},
+		{
+			name:         "escaped path parameter",
+			method:       http.MethodGet,
+			path:         "/products/soap%2Fbar",
+			expectedCode: http.StatusOK,
+			expectedBody: "/products/{id} map[id:soap/bar]",
+		},
+		{
+			name:         "escaped literal segment",
+			method:       http.MethodGet,
+			path:         "/caf%C3%A9/1",
+			expectedCode: http.StatusOK,
+			expectedBody: "/café/{x} map[x:1]",
+		},
+		{
+			name:         "literal wins over parameter",
+			method:       http.MethodGet,
+			path:         "/products/featured",
+			expectedCode: http.StatusOK,
+			expectedBody: "/products/featured map[]",
+		},
+		{
+			name:         "multiple parameters",
+			method:       http.MethodGet,
+			path:         "/products/42/reviews/7",
+			expectedCode: http.StatusOK,
+			expectedBody: "/products/{id}/reviews/{review} map[id:42 review:7]",
+		},
+		{
+			name:         "head falls back to get",
+			method:       http.MethodHead,
+			path:         "/products/42",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "method not allowed",
+			method:        http.MethodDelete,
+			path:          "/products",
+			expectedCode:  http.StatusMethodNotAllowed,
+			expectedAllow: "GET, POST",
+		},
+		{
+			name:         "not found",
+			method:       http.MethodGet,
+			path:         "/customers",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+
+			if rec.Code != tc.expectedCode {
+				t.Fatalf("expected code %d got %d", tc.expectedCode, rec.Code)
+			}
+			if allow := rec.Header().Get("Allow"); allow != tc.expectedAllow {
+				t.Errorf("expected Allow %q got %q", tc.expectedAllow, allow)
+			}
+
+			if tc.expectedCode != http.StatusOK {
+				var resp response.Response
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.Status != response.StatusFail || resp.Code != tc.expectedCode {
+					t.Errorf("expected a fail envelope with code %d got %+v", tc.expectedCode, resp)
+				}
+				return
+			}
+			if tc.method != http.MethodHead && rec.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %q got %q", tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouter_Handle(t *testing.T) {
+	tt := []struct {
+		name   string
+		routes []Route
+	}{
+		{
+			name:   "missing leading slash",
+			routes: []Route{echoRoute(http.MethodGet, "products")},
+		},
+		{
+			name:   "malformed parameter",
+			routes: []Route{echoRoute(http.MethodGet, "/products/{id")},
+		},
+		{
+			name:   "repeated parameter",
+			routes: []Route{echoRoute(http.MethodGet, "/products/{id}/{id}")},
+		},
+		{
+			name:   "missing method",
+			routes: []Route{echoRoute("", "/products")},
+		},
+		{
+			name: "duplicate route",
+			routes: []Route{
+				echoRoute(http.MethodGet, "/products"),
+				echoRoute(http.MethodGet, "/products"),
+			},
+		},
+		{
+			name: "conflicting parameter names",
+			routes: []Route{
+				echoRoute(http.MethodGet, "/products/{id}"),
+				echoRoute(http.MethodPut, "/products/{sku}"),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewRouter(nil, tc.routes); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestRouter_FillsEndpoints(t *testing.T) {
+	info := &MicroserviceInfo{}
+	router, err := NewRouter(info, []Route{echoRoute(http.MethodGet, "/products")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Handle(echoRoute(http.MethodGet, "/products/{id}")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(info.Endpoints) != 2 {
+		t.Fatalf("expected 2 endpoints got %d", len(info.Endpoints))
+	}
+	if info.Endpoints[1].Path != "/products/{id}" {
+		t.Errorf("expected /products/{id} got %s", info.Endpoints[1].Path)
+	}
+}
+
+func TestParams_Int(t *testing.T) {
+	params := Params{"id": "42", "name": "soap"}
+
+	if id, err := params.Int("id"); err != nil || id != 42 {
+		t.Errorf("expected 42 got %d (%v)", id, err)
+	}
+	if _, err := params.Int("name"); err == nil {
+		t.Error("expected an error for a non-numeric parameter")
+	}
+	if _, err := params.Int64("missing"); err == nil {
+		t.Error("expected an error for a missing parameter")
+	}
+}
+
+// versionedRoute - Route writing its label and the dispatched version back.
+func versionedRoute(label string, versions ...int) Route {
+	return Route{
+		Path:     "/products",
+		Method:   http.MethodGet,
+		Versions: versions,
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s %d", label, RequestVersion(r))
+		},
+	}
+}
+
+func TestRouter_Versions(t *testing.T) {
+	tt := []struct {
+		name         string
+		routes       []Route
+		header       string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "unversioned route ignores header",
+			routes:       []Route{versionedRoute("any")},
+			header:       "3",
+			expectedCode: http.StatusOK,
+			expectedBody: "any 0",
+		},
+		{
+			name:         "header selects version",
+			routes:       []Route{versionedRoute("old", 1), versionedRoute("new", 2, 3)},
+			header:       "1",
+			expectedCode: http.StatusOK,
+			expectedBody: "old 1",
+		},
+		{
+			name:         "route serving several versions",
+			routes:       []Route{versionedRoute("old", 1), versionedRoute("new", 2, 3)},
+			header:       "3",
+			expectedCode: http.StatusOK,
+			expectedBody: "new 3",
+		},
+		{
+			name:         "no header selects latest",
+			routes:       []Route{versionedRoute("new", 2), versionedRoute("old", 1)},
+			expectedCode: http.StatusOK,
+			expectedBody: "new 2",
+		},
+		{
+			name:         "no header prefers unversioned",
+			routes:       []Route{versionedRoute("new", 2), versionedRoute("any")},
+			expectedCode: http.StatusOK,
+			expectedBody: "any 0",
+		},
+		{
+			name:         "unknown version",
+			routes:       []Route{versionedRoute("old", 1), versionedRoute("new", 2)},
+			header:       "4",
+			expectedCode: http.StatusNotAcceptable,
+		},
+		{
+			name:         "malformed version",
+			routes:       []Route{versionedRoute("old", 1)},
+			header:       "latest",
+			expectedCode: http.StatusNotAcceptable,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			router, err := NewRouter(nil, tc.routes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/products", nil)
+			if tc.header != "" {
+				req.Header.Set(config.ServiceVersionHeader, tc.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedCode {
+				t.Fatalf("expected code %d got %d", tc.expectedCode, rec.Code)
+			}
+			if tc.expectedCode != http.StatusOK {
+				var resp response.Response
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.Status != response.StatusFail {
+					t.Errorf("expected a fail envelope got %+v", resp)
+				}
+				return
+			}
+			if rec.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %q got %q", tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouter_OverlappingVersions(t *testing.T) {
+	_, err := NewRouter(nil, []Route{versionedRoute("a", 1, 2), versionedRoute("b", 2)})
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,235 @@
+package microservicecore
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/LUSHDigital/microservice-core-golang/env"
+)
+
+const (
+	// DefaultPort - Port the server listens on when SERVICE_PORT is not set.
+	DefaultPort = 8080
+
+	// DefaultGracePeriod - How long in-flight requests are given to complete
+	// once the server starts shutting down.
+	DefaultGracePeriod = 30 * time.Second
+)
+
+// ShutdownHook - Releases a resource once the server has stopped serving.
+type ShutdownHook func(ctx context.Context) error
+
+// shutdownHook - A registered shutdown hook.
+type shutdownHook struct {
+	name string
+	hook ShutdownHook
+}
+
+// ServerOption - Configures a Server.
+type ServerOption func(*Server)
+
+// WithPort - Listen on the provided port instead of SERVICE_PORT.
+func WithPort(port int) ServerOption {
+	return func(s *Server) {
+		s.port = port
+	}
+}
+
+// WithGracePeriod - Give in-flight requests the provided duration to complete
+// on shutdown instead of DefaultGracePeriod.
+func WithGracePeriod(gracePeriod time.Duration) ServerOption {
+	return func(s *Server) {
+		s.gracePeriod = gracePeriod
+	}
+}
+
+// WithDrainDelay - Keep serving for the provided duration after readiness
+// starts failing on shutdown, so readiness probes see the failing report and
+// traffic is moved away before the listeners close. Without a delay, the
+// failing report is only visible to requests already in flight.
+func WithDrainDelay(drainDelay time.Duration) ServerOption {
+	return func(s *Server) {
+		s.drainDelay = drainDelay
+	}
+}
+
+// WithHealth - Use the provided health registry instead of an empty one.
+func WithHealth(health *Health) ServerOption {
+	return func(s *Server) {
+		s.Health = health
+	}
+}
+
+// Server - Serves the routes of this microservice, alongside its info and
+// health routes, until it is told to shut down.
+type Server struct {
+	Info   *MicroserviceInfo // Information about this microservice.
+	Router *Router           // Router serving every route.
+	Health *Health           // Health registry backing the health routes.
+
+	port        int
+	gracePeriod time.Duration
+	drainDelay  time.Duration
+	server      *http.Server
+
+	mu       sync.Mutex
+	listener net.Listener
+	hooks    []shutdownHook
+}
+
+// NewServer - Prepare a server for the provided routes. The port is read from
+// SERVICE_PORT, falling back to DefaultPort, unless WithPort is used, in which
+// case SERVICE_PORT is not declared to env.
+func NewServer(info *MicroserviceInfo, routes []Route, opts ...ServerOption) (*Server, error) {
+	s := &Server{
+		Info:        info,
+		gracePeriod: DefaultGracePeriod,
+		port:        -1,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.port < 0 {
+		env.Declare(env.Declaration{
+			Name:        "SERVICE_PORT",
+			Description: "Port the server listens on.",
+			Default:     strconv.Itoa(DefaultPort),
+		})
+		port, err := env.GetInt("SERVICE_PORT", DefaultPort, env.Range(0, 65535))
+		if err != nil {
+			return nil, err
+		}
+		s.port = port
+	}
+	if s.Health == nil {
+		s.Health = NewHealth()
+	}
+
+	router, err := NewRouter(info, routes)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range []Route{
+		InfoRoute(info, router),
+		s.Health.LivenessRoute(),
+		s.Health.ReadinessRoute(),
+	} {
+		if err := router.Handle(route); err != nil {
+			return nil, err
+		}
+	}
+	s.Router = router
+
+	s.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", s.port),
+		Handler: router,
+	}
+	return s, nil
+}
+
+// OnShutdown - Register a hook to run once in-flight requests have drained.
+// Hooks run in the order they were registered.
+func (s *Server) OnShutdown(name string, hook ShutdownHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, shutdownHook{name: name, hook: hook})
+}
+
+// Addr - Get the address the server is listening on, or the address it will
+// listen on when it has not started yet.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.server.Addr
+}
+
+// Serve - Accept connections on the provided listener until the server is
+// shut down.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+
+	if err := s.server.Serve(l); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// ListenAndServe - Listen on the configured port until the server is shut down.
+func (s *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Run - Listen on the configured port until SIGTERM or SIGINT is received,
+// then shut down gracefully.
+func (s *Server) Run() error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case sig := <-signals:
+		log.Printf("received %s, shutting down", sig)
+	}
+	return s.Shutdown()
+}
+
+// Shutdown - Fail readiness, keep serving for the drain delay, drain
+// in-flight requests within the grace period and then run every shutdown
+// hook in order.
+func (s *Server) Shutdown() error {
+	s.Health.Drain()
+	time.Sleep(s.drainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.gracePeriod)
+	defer cancel()
+
+	var problems []string
+	if err := s.server.Shutdown(ctx); err != nil {
+		problems = append(problems, fmt.Sprintf("cannot drain connections: %v", err))
+	}
+
+	// Hooks get their own grace period, so a slow drain cannot starve them.
+	hookCtx, hookCancel := context.WithTimeout(context.Background(), s.gracePeriod)
+	defer hookCancel()
+
+	s.mu.Lock()
+	hooks := s.hooks
+	s.mu.Unlock()
+	for _, h := range hooks {
+		if err := h.hook(hookCtx); err != nil {
+			problems = append(problems, fmt.Sprintf("shutdown hook (%s) failed: %v", h.name, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("cannot shut down cleanly: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package microservicecore
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewServer_Port(t *testing.T) {
+	tt := []struct {
+		name         string
+		env          string
+		opts         []ServerOption
+		expectedAddr string
+		expectedErr  bool
+	}{
+		{
+			name:         "default port",
+			expectedAddr: ":8080",
+		},
+		{
+			name:         "port from env",
+			env:          "9000",
+			expectedAddr: ":9000",
+		},
+		{
+			name:         "port from option",
+			env:          "9000",
+			opts:         []ServerOption{WithPort(9100)},
+			expectedAddr: ":9100",
+		},
+		{
+			name:        "invalid port",
+			env:         "http",
+			expectedErr: true,
+		},
+		{
+			name:        "port out of range",
+			env:         "99999",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Unsetenv("SERVICE_PORT")
+			if tc.env != "" {
+				os.Setenv("SERVICE_PORT", tc.env)
+				defer os.Unsetenv("SERVICE_PORT")
+			}
+
+			server, err := NewServer(&MicroserviceInfo{}, nil, tc.opts...)
+			if tc.expectedErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if server.Addr() != tc.expectedAddr {
+				t.Errorf("expected %s got %s", tc.expectedAddr, server.Addr())
+			}
+		})
+	}
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	started := make(chan struct{})
+	slow := Route{
+		Path:   "/slow",
+		Method: http.MethodGet,
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte("done"))
+		},
+	}
+
+	server, err := NewServer(&MicroserviceInfo{}, []Route{slow}, WithGracePeriod(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var order []string
+	server.OnShutdown("db", func(ctx context.Context) error {
+		if server.Health.Readiness(ctx).Status != HealthStatusFail {
+			t.Error("expected readiness to fail during shutdown")
+		}
+		order = append(order, "db")
+		return nil
+	})
+	server.OnShutdown("metrics", func(ctx context.Context) error {
+		order = append(order, "metrics")
+		return errors.New("flush failed")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(l)
+	}()
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + l.Addr().String() + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+
+	<-started
+	if err := server.Shutdown(); err == nil {
+		t.Error("expected the failing hook to be reported")
+	}
+
+	if b := <-body; b != "done" {
+		t.Errorf("expected the in-flight request to complete got %q", b)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("unexpected serve error: %v", err)
+	}
+	if expected := []string{"db", "metrics"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected hooks to run in order %v got %v", expected, order)
+	}
+}
+
+func TestServer_DrainDelay(t *testing.T) {
+	server, err := NewServer(&MicroserviceInfo{}, nil, WithDrainDelay(200*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(l)
+	}()
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown()
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := http.Get("http://" + l.Addr().String() + ReadinessPath)
+	if err != nil {
+		t.Fatalf("expected the server to keep serving during the drain delay: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected readiness to fail during the drain delay got %d", resp.StatusCode)
+	}
+
+	if err := <-shutdown; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("unexpected serve error: %v", err)
+	}
+}
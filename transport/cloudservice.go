@@ -8,7 +8,10 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/LUSHDigital/microservice-core-golang/env"
 	"github.com/LUSHDigital/microservice-core-golang/response"
 	"github.com/LUSHDigital/microservice-core-golang/transport/models"
 	transportErrors "github.com/LUSHDigital/microservice-core-golang/transport/errors"
@@ -19,8 +22,32 @@ import (
 
 // AuthCredentials - Credentials needed to authenticate for a cloud service.
 type AuthCredentials struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" env:"SOA_AUTH_EMAIL,required" desc:"Email to log in to the API gateway with."`
+	Password string `json:"password" env:"SOA_AUTH_PASSWORD,required,secret" desc:"Password to log in to the API gateway with."`
+}
+
+// String - Format the credentials with the password masked.
+func (a AuthCredentials) String() string {
+	return fmt.Sprintf("{Email:%s Password:%s}", a.Email, env.Secret(a.Password))
+}
+
+// LoadAuthCredentials - Load the credentials from the environment. Either
+// variable can be provided as a file instead, using SOA_AUTH_PASSWORD_FILE
+// for example.
+func LoadAuthCredentials() (*AuthCredentials, error) {
+	credentials := &AuthCredentials{}
+	if err := env.Load(credentials); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// CloudConfig - Environment a cloud service is configured with. Keep it in
+// an env.Store subscribed to CloudService.Configure to pick up rotated
+// credentials and new timeouts without a restart.
+type CloudConfig struct {
+	Credentials AuthCredentials `prefix:""`
+	Timeout     time.Duration   `env:"SOA_CLIENT_TIMEOUT" default:"5s" desc:"Timeout of cloud service calls."`
 }
 
 // CloudService - Responsible for communication with a cloud service.
@@ -28,6 +55,14 @@ type CloudService struct {
 	Service                      // Inherit all properties of a normal service.
 	Credentials *AuthCredentials // Authentication credentials for cloud service calls.
 	Client      *http.Client
+
+	configured atomic.Value // Credentials and client set by Configure.
+}
+
+// cloudSettings - Credentials and client set by Configure.
+type cloudSettings struct {
+	credentials *AuthCredentials
+	client      *http.Client
 }
 
 // NewCloudService - Prepare a new CloudService struct with the provided parameters.
@@ -47,10 +82,36 @@ func NewCloudService(client *http.Client, branch, env, namespace, name string, c
 	}
 }
 
+// Configure - Use the credentials and timeout of a config for subsequent
+// calls, in place of Credentials and the timeout of Client. Safe to call
+// while the service is in use.
+func (c *CloudService) Configure(cfg *CloudConfig) {
+	credentials := cfg.Credentials
+
+	// Copy the client, as it may be in use or shared with other services.
+	client := DefaultHTTPClient()
+	if c.Client != nil {
+		*client = *c.Client
+	}
+	client.Timeout = cfg.Timeout
+
+	c.configured.Store(cloudSettings{credentials: &credentials, client: client})
+}
+
+// current - Get the credentials and client to use for a call.
+func (c *CloudService) current() (*AuthCredentials, *http.Client) {
+	if settings, ok := c.configured.Load().(cloudSettings); ok {
+		return settings.credentials, settings.client
+	}
+	return c.Credentials, c.Client
+}
+
 // authenticate - Authenticate against the API gateway and return an auth token.
 func (c *CloudService) authenticate(request *Request) (*models.Token, error) {
+	credentials, client := c.current()
+
 	// loginBody := new(bytes.Buffer)
-	loginBody, err := json.Marshal(c.Credentials)
+	loginBody, err := json.Marshal(credentials)
 	if err != nil {
 		return nil, fmt.Errorf("cannot encode json: %s", err)
 	}
@@ -60,7 +121,7 @@ func (c *CloudService) authenticate(request *Request) (*models.Token, error) {
 		return nil, fmt.Errorf("cannot build login request: %s", err)
 	}
 
-	loginResp, err := c.Client.Do(loginReq)
+	loginResp, err := client.Do(loginReq)
 	if err != nil {
 		return nil, fmt.Errorf("cannot perform login request: %s", err)
 	}
@@ -122,12 +183,13 @@ func (c *CloudService) GetAPIGatewayURL(request *Request) string {
 
 // Call - Do the current service request.
 func (c *CloudService) Call() (*http.Response, error) {
-	return c.Client.Do(c.CurrentRequest)
+	_, client := c.current()
+	return client.Do(c.CurrentRequest)
 }
 
 // Dial - Create a request to a service resource.
 func (c *CloudService) Dial(request *Request) error {
-	if c.Credentials.Email == "" || c.Credentials.Password == "" {
+	if credentials, _ := c.current(); credentials == nil || credentials.Email == "" || credentials.Password == "" {
 		return errors.New("cannot authenticate for cloud service: missing credentials")
 	}
 
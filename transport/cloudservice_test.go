@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -834,3 +835,79 @@ func ExampleCloudService_Call_headers() {
 		fmt.Println("response err: internal server error")
 	}
 }
+
+func TestLoadAuthCredentials(t *testing.T) {
+	passwordFile, err := ioutil.TempFile("", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(passwordFile.Name())
+	passwordFile.WriteString("s3cret\n")
+	passwordFile.Close()
+
+	os.Setenv("SOA_AUTH_EMAIL", "service@example.com")
+	os.Unsetenv("SOA_AUTH_PASSWORD")
+	os.Setenv("SOA_AUTH_PASSWORD_FILE", passwordFile.Name())
+	defer os.Unsetenv("SOA_AUTH_EMAIL")
+	defer os.Unsetenv("SOA_AUTH_PASSWORD_FILE")
+
+	credentials, err := LoadAuthCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credentials.Email != "service@example.com" || credentials.Password != "s3cret" {
+		t.Errorf("TestLoadAuthCredentials: unexpected credentials %#v", *credentials)
+	}
+	if formatted := fmt.Sprint(credentials); strings.Contains(formatted, "s3cret") {
+		t.Errorf("TestLoadAuthCredentials: expected the password to be masked got %s", formatted)
+	}
+}
+
+func TestCloudService_Configure(t *testing.T) {
+	var logins []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var credentials AuthCredentials
+		json.NewDecoder(r.Body).Decode(&credentials)
+		logins = append(logins, credentials.Password)
+
+		resp := response.New(http.StatusOK, "", &response.Data{
+			Type: "consumer",
+			Content: models.Consumer{
+				Tokens: []*models.Token{{Type: "JWT", Value: "xxxx.xxxx.xxxx"}},
+			},
+		})
+		resp.WriteTo(w)
+	}))
+	defer ts.Close()
+
+	os.Setenv("SOA_GATEWAY_URL", ts.URL)
+	defer os.Unsetenv("SOA_GATEWAY_URL")
+
+	client := DefaultHTTPClient()
+	service := NewCloudService(client, "master", "staging", "services", "myservice", &AuthCredentials{
+		Email:    "test@test.com",
+		Password: "old",
+	})
+	request := &Request{Method: http.MethodGet, Resource: "things", Protocol: config.ProtocolHTTP}
+
+	if err := service.Dial(request); err != nil {
+		t.Fatal(err)
+	}
+	service.Configure(&CloudConfig{
+		Credentials: AuthCredentials{Email: "test@test.com", Password: "rotated"},
+		Timeout:     time.Second,
+	})
+	if err := service.Dial(request); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(logins, ",") != "old,rotated" {
+		t.Errorf("TestCloudService_Configure: expected logins with old,rotated got %v", logins)
+	}
+	if _, current := service.current(); current.Timeout != time.Second {
+		t.Errorf("TestCloudService_Configure: expected a 1s timeout got %s", current.Timeout)
+	}
+	if client.Timeout != 5*time.Second {
+		t.Errorf("TestCloudService_Configure: expected the original client to be left untouched got %s", client.Timeout)
+	}
+}
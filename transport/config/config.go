@@ -1,6 +1,6 @@
 package config
 
-import "os"
+import "github.com/LUSHDigital/microservice-core-golang/env"
 
 const (
 	// AuthHeader - Name of the HTTP header to use for authentication and
@@ -27,17 +27,33 @@ const (
 	AggregatorDomainPrefix = "agg"
 )
 
+// Config - Environment used to locate other services.
+type Config struct {
+	ServiceDomain string `env:"SOA_DOMAIN" desc:"Top level domain of the service environment."`
+	GatewayURI    string `env:"SOA_GATEWAY_URI" desc:"URI of the API gateway."`
+	GatewayURL    string `env:"SOA_GATEWAY_URL" desc:"Full URL of the API gateway, used instead of SOA_GATEWAY_URI and SOA_DOMAIN."`
+}
+
+// Load - Load the config from the environment, declaring its variables to env.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	if err := env.Load(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
 // GetServiceDomain - Get the top level domain of the service environment.
 func GetServiceDomain() string {
-	return os.Getenv("SOA_DOMAIN")
+	return env.Get("SOA_DOMAIN", "")
 }
 
 // GetGatewayURI - Get the URI of the API gateway.
 func GetGatewayURI() string {
-	return os.Getenv("SOA_GATEWAY_URI")
+	return env.Get("SOA_GATEWAY_URI", "")
 }
 
 // GetGatewayURL - Get the URL of the API gateway.
 func GetGatewayURL() string {
-	return os.Getenv("SOA_GATEWAY_URL")
+	return env.Get("SOA_GATEWAY_URL", "")
 }
@@ -97,3 +97,22 @@ func TestGetGatewayUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad(t *testing.T) {
+	os.Setenv("SOA_DOMAIN", "example.com")
+	os.Setenv("SOA_GATEWAY_URI", "gateway")
+	os.Setenv("SOA_GATEWAY_URL", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Config{
+		ServiceDomain: "example.com",
+		GatewayURI:    "gateway",
+	}
+	if *cfg != expected {
+		t.Errorf("TestLoad: expected %+v got %+v", expected, *cfg)
+	}
+}
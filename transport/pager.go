@@ -0,0 +1,301 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/LUSHDigital/microservice-core-golang/pagination"
+)
+
+// DefaultCursorParam - Query parameter the cursor of the next page is passed in.
+const DefaultCursorParam = "cursor"
+
+// ErrMaxPages - Error returned when more pages remain after the maximum number
+// of pages has been fetched.
+var ErrMaxPages = errors.New("cannot fetch page: maximum number of pages reached")
+
+// PageOptions - Configures how a PageIterator follows the pages of a service.
+type PageOptions struct {
+	PageParam   string // Query parameter holding the page number, pagination.DefaultPageParam when empty.
+	CursorParam string // Query parameter holding the cursor, DefaultCursorParam when empty.
+	Type        string // Name of the data collection, required when a page holds several.
+	MaxPages    int    // Number of pages fetched before failing with ErrMaxPages, unlimited when zero.
+	Prefetch    int    // Number of pages fetched ahead in the background, none when zero.
+}
+
+// PageIterator - Iterates over the items of a paginated collection served by
+// another service, following the next page or the next cursor of each page:
+//
+//	items := transport.NewPageIterator(ctx, service, &transport.Request{
+//	    Method:   http.MethodGet,
+//	    Resource: "products",
+//	}, transport.PageOptions{MaxPages: 50, Prefetch: 2})
+//	defer items.Close()
+//	for items.Next() {
+//	    var product Product
+//	    if err := items.Decode(&product); err != nil {
+//	        return err
+//	    }
+//	}
+//	if err := items.Err(); err != nil {
+//	    return err
+//	}
+type PageIterator struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	pager   *pager
+	pages   chan fetchedPage // Pages fetched in the background, nil without prefetch.
+	fetcher chan struct{}    // Closed once the background fetches have stopped.
+
+	items []json.RawMessage // Items of the current page not iterated yet.
+	item  json.RawMessage   // The current item.
+	err   error
+	done  bool
+}
+
+// NewPageIterator - Prepare an iterator over the items of a paginated
+// collection. Pages are requested through the transport with the method,
+// headers and query of the request; its body, if any, is only sent with the
+// first page. The transport must not be used elsewhere until Next has
+// returned false or Close has returned.
+func NewPageIterator(ctx context.Context, transport Transport, request *Request, opts PageOptions) *PageIterator {
+	if opts.PageParam == "" {
+		opts.PageParam = pagination.DefaultPageParam
+	}
+	if opts.CursorParam == "" {
+		opts.CursorParam = DefaultCursorParam
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	it := &PageIterator{
+		ctx:    ctx,
+		cancel: cancel,
+		pager: &pager{
+			transport: transport,
+			request:   *request,
+			opts:      opts,
+		},
+	}
+	if opts.Prefetch > 0 {
+		it.pages = make(chan fetchedPage, opts.Prefetch-1)
+		it.fetcher = make(chan struct{})
+		go it.prefetch()
+	}
+	return it
+}
+
+// Next - Advance to the next item, fetching the next page when needed.
+// Returns false when every item has been iterated, the context is done or a
+// page cannot be fetched, which Err tells apart.
+func (it *PageIterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.err != nil || it.done {
+			return false
+		}
+		page, ok := it.nextPage()
+		switch {
+		case page.err != nil:
+			it.err = page.err
+			it.stop()
+		case !ok:
+			it.done = true
+			it.stop()
+		default:
+			it.items = page.items
+		}
+	}
+	it.item, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Decode - Decode the current item into dst.
+func (it *PageIterator) Decode(dst interface{}) error {
+	if it.item == nil {
+		return errors.New("cannot decode item: call Next first")
+	}
+	return json.Unmarshal(it.item, dst)
+}
+
+// Err - Get the error that stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+// Close - Stop the iteration, waiting for any page being fetched in the
+// background, so the transport can be used again once it returns.
+func (it *PageIterator) Close() {
+	it.done = true
+	it.stop()
+}
+
+// stop - Cancel the fetches and wait for the background ones to stop.
+func (it *PageIterator) stop() {
+	it.cancel()
+	if it.fetcher != nil {
+		<-it.fetcher
+	}
+}
+
+// nextPage - Get the next page, reporting false when there is none.
+func (it *PageIterator) nextPage() (fetchedPage, bool) {
+	if it.pages == nil {
+		if it.pager.done {
+			return fetchedPage{}, false
+		}
+		if err := it.ctx.Err(); err != nil {
+			return fetchedPage{err: err}, true
+		}
+		return it.pager.fetch(it.ctx), true
+	}
+
+	select {
+	case <-it.ctx.Done():
+		return fetchedPage{err: it.ctx.Err()}, true
+	case page, ok := <-it.pages:
+		if !ok && it.ctx.Err() != nil {
+			return fetchedPage{err: it.ctx.Err()}, true
+		}
+		return page, ok
+	}
+}
+
+// prefetch - Fetch pages in the background until the last one, the first
+// failure or the end of the context.
+func (it *PageIterator) prefetch() {
+	defer close(it.fetcher)
+	defer close(it.pages)
+	for !it.pager.done {
+		page := it.pager.fetch(it.ctx)
+		select {
+		case it.pages <- page:
+		case <-it.ctx.Done():
+			return
+		}
+		if page.err != nil {
+			return
+		}
+	}
+}
+
+// fetchedPage - Items of a page, or why it could not be fetched.
+type fetchedPage struct {
+	items []json.RawMessage
+	err   error
+}
+
+// pager - Fetches the pages of a collection one after another.
+type pager struct {
+	transport Transport
+	request   Request
+	opts      PageOptions
+	fetched   int  // Number of pages fetched so far.
+	done      bool // Whether the last page has been fetched.
+}
+
+// pageResponse - Paginated response of a service, with its data left encoded.
+type pageResponse struct {
+	Message    string                     `json:"message"`
+	Data       map[string]json.RawMessage `json:"data"`
+	Pagination *pagination.Response       `json:"pagination"`
+	Cursor     *pagination.CursorResponse `json:"cursor"`
+}
+
+// fetch - Fetch the next page and prepare the request of the one after it.
+func (p *pager) fetch(ctx context.Context) fetchedPage {
+	if p.opts.MaxPages > 0 && p.fetched >= p.opts.MaxPages {
+		return fetchedPage{err: ErrMaxPages}
+	}
+	p.fetched++
+
+	resp, err := p.call(ctx)
+	if err != nil {
+		return fetchedPage{err: fmt.Errorf("cannot fetch page %d of %s: %v", p.fetched, p.transport.GetName(), err)}
+	}
+	items, err := p.decode(resp)
+	if err != nil {
+		return fetchedPage{err: fmt.Errorf("cannot read page %d of %s: %v", p.fetched, p.transport.GetName(), err)}
+	}
+	return fetchedPage{items: items}
+}
+
+// call - Dial and call the transport. Transports cannot abort a call, so a
+// call in flight when the context is done completes before its response is
+// discarded.
+func (p *pager) call(ctx context.Context) (*http.Response, error) {
+	if err := p.transport.Dial(&p.request); err != nil {
+		return nil, err
+	}
+	p.request.Body = nil
+
+	resp, err := p.transport.Call()
+	if ctx.Err() != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, ctx.Err()
+	}
+	return resp, err
+}
+
+// decode - Decode the items of a page and point the request at the next one.
+func (p *pager) decode(resp *http.Response) ([]json.RawMessage, error) {
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read response body: %v", err)
+	}
+
+	var page pageResponse
+	decodeErr := json.Unmarshal(content, &page)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, page.Message)
+	}
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	var items []json.RawMessage
+	if collection, err := p.collection(page.Data); err != nil {
+		return nil, err
+	} else if collection != nil {
+		if err := json.Unmarshal(collection, &items); err != nil {
+			return nil, fmt.Errorf("collection is not a list: %v", err)
+		}
+	}
+
+	query := url.Values{}
+	for key, values := range p.request.Query {
+		query[key] = values
+	}
+	switch {
+	case page.Pagination != nil && page.Pagination.NextPage != nil:
+		query.Set(p.opts.PageParam, strconv.Itoa(*page.Pagination.NextPage))
+	case page.Cursor != nil && page.Cursor.NextCursor != nil:
+		query.Set(p.opts.CursorParam, *page.Cursor.NextCursor)
+	default:
+		p.done = true
+	}
+	p.request.Query = query
+	return items, nil
+}
+
+// collection - Get the encoded data collection of a page, which is the only
+// one unless a type is configured.
+func (p *pager) collection(data map[string]json.RawMessage) (json.RawMessage, error) {
+	if p.opts.Type != "" {
+		return data[p.opts.Type], nil
+	}
+	if len(data) > 1 {
+		return nil, errors.New("page holds several collections, set the type to iterate over")
+	}
+	for _, collection := range data {
+		return collection, nil
+	}
+	return nil, nil
+}
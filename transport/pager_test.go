@@ -0,0 +1,190 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LUSHDigital/microservice-core-golang/pagination"
+	"github.com/LUSHDigital/microservice-core-golang/response"
+)
+
+// testTransport - Transport calling a test server.
+type testTransport struct {
+	url     string
+	request *http.Request
+}
+
+func (t *testTransport) Dial(request *Request) error {
+	var err error
+	t.request, err = http.NewRequest(request.Method, fmt.Sprintf("%s/%s?%s", t.url, request.Resource, request.Query.Encode()), request.Body)
+	return err
+}
+
+func (t *testTransport) Call() (*http.Response, error) {
+	return http.DefaultClient.Do(t.request)
+}
+
+func (t *testTransport) GetName() string {
+	return "test"
+}
+
+func TestPageIterator(t *testing.T) {
+	products := []string{"a", "b", "c", "d", "e"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/products", func(w http.ResponseWriter, r *http.Request) {
+		paginator, err := pagination.FromRequest(r, pagination.Options{DefaultPerPage: 2})
+		if err != nil {
+			response.New(http.StatusBadRequest, err.Error(), nil).WriteTo(w)
+			return
+		}
+		page, err := pagination.Slice(products, paginator)
+		if err != nil {
+			response.New(http.StatusInternalServerError, err.Error(), nil).WriteTo(w)
+			return
+		}
+		response.NewPaginated(paginator, http.StatusOK, "", &response.Data{Type: "products", Content: page}).WriteTo(w)
+	})
+	mux.HandleFunc("/cursored", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprint(w, `{"data":{"products":["a","b"]},"cursor":{"per_page":2,"next_cursor":"c1"}}`)
+		case "c1":
+			fmt.Fprint(w, `{"data":{"products":["c"]},"cursor":{"per_page":2,"next_cursor":null}}`)
+		}
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		fmt.Fprint(w, `{"data":{"products":["a"]},"pagination":{"current_page":1,"next_page":2}}`)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		response.New(http.StatusInternalServerError, "database is down", nil).WriteTo(w)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tt := []struct {
+		name          string
+		resource      string
+		opts          PageOptions
+		expectedItems []string
+		expectedErr   string
+	}{
+		{
+			name:          "Follow page numbers",
+			resource:      "products",
+			expectedItems: products,
+		},
+		{
+			name:          "Follow page numbers with prefetch",
+			resource:      "products",
+			opts:          PageOptions{Prefetch: 2},
+			expectedItems: products,
+		},
+		{
+			name:          "Follow cursors",
+			resource:      "cursored",
+			opts:          PageOptions{Type: "products"},
+			expectedItems: []string{"a", "b", "c"},
+		},
+		{
+			name:          "Stop at the maximum number of pages",
+			resource:      "products",
+			opts:          PageOptions{MaxPages: 2, Prefetch: 1},
+			expectedItems: []string{"a", "b", "c", "d"},
+			expectedErr:   ErrMaxPages.Error(),
+		},
+		{
+			name:        "Fail on an error response",
+			resource:    "broken",
+			expectedErr: "cannot read page 1 of test: unexpected status 500: database is down",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			items := NewPageIterator(context.Background(), &testTransport{url: server.URL}, &Request{
+				Method:   http.MethodGet,
+				Resource: tc.resource,
+			}, tc.opts)
+			defer items.Close()
+
+			var got []string
+			for items.Next() {
+				var item string
+				if err := items.Decode(&item); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, item)
+			}
+			if !reflect.DeepEqual(got, tc.expectedItems) {
+				t.Errorf("expected items %v got %v", tc.expectedItems, got)
+			}
+
+			err := items.Err()
+			if tc.expectedErr == "" && err != nil {
+				t.Errorf("expected no error got %v", err)
+			}
+			if tc.expectedErr != "" && (err == nil || err.Error() != tc.expectedErr) {
+				t.Errorf("expected error %s got %v", tc.expectedErr, err)
+			}
+		})
+	}
+
+	t.Run("Stop when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		items := NewPageIterator(ctx, &testTransport{url: server.URL}, &Request{
+			Method:   http.MethodGet,
+			Resource: "products",
+		}, PageOptions{})
+		defer items.Close()
+
+		var got []string
+		for items.Next() {
+			var item string
+			if err := items.Decode(&item); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, item)
+			cancel()
+		}
+		if strings.Join(got, "") != "ab" {
+			t.Errorf("expected the first page only got %v", got)
+		}
+		if err := items.Err(); err != context.Canceled {
+			t.Errorf("expected the context error got %v", err)
+		}
+	})
+
+	t.Run("Close waits for the page being prefetched", func(t *testing.T) {
+		transport := &testTransport{url: server.URL}
+		items := NewPageIterator(context.Background(), transport, &Request{
+			Method:   http.MethodGet,
+			Resource: "slow",
+		}, PageOptions{Prefetch: 2})
+		if !items.Next() {
+			t.Fatalf("expected an item got %v", items.Err())
+		}
+		items.Close()
+
+		// The race detector reports the prefetch still using the transport.
+		if err := transport.Dial(&Request{Method: http.MethodGet, Resource: "products"}); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := transport.Call()
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if items.Next() {
+			t.Error("expected no items once closed")
+		}
+	})
+}